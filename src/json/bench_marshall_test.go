@@ -0,0 +1,26 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Benchmark_MarshallStruct(b *testing.B) {
+	var capture any
+	item := Item{Name: "apple", Quantity: 3, Price: 0.5}
+	order := Order{Base: Base{ID: 7}, Items: []Item{item, item, item}, Tags: [2]string{"a", "b"}}
+	for n := 0; n < b.N; n++ {
+		capture, _ = Marshall(order)
+	}
+	res = capture
+}
+
+func Benchmark_MarshallStruct_Stdlib(b *testing.B) {
+	var capture any
+	item := Item{Name: "apple", Quantity: 3, Price: 0.5}
+	order := Order{Base: Base{ID: 7}, Items: []Item{item, item, item}, Tags: [2]string{"a", "b"}}
+	for n := 0; n < b.N; n++ {
+		capture, _ = json.Marshal(order)
+	}
+	res = capture
+}
@@ -0,0 +1,185 @@
+package json
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, coefficient * 10^-scale, for values like amounts of money
+// where float64 would round 0.1 to something else. The coefficient can be as big as needed.
+// Decoding with DecodeOptions{Numbers: DecimalNumbers} produces it and Marshall writes a decoded
+// Decimal back exactly as it was in the json. The zero value is 0.
+type Decimal struct {
+	coefficient *big.Int
+	scale       int
+	// literal is the json the Decimal was parsed from, if it hasn't been changed since
+	literal string
+}
+
+// NewDecimal returns coefficient * 10^-scale
+func NewDecimal(coefficient *big.Int, scale int) Decimal {
+	return Decimal{coefficient: new(big.Int).Set(coefficient), scale: scale}
+}
+
+// maxDecimalScale is the furthest the scale of a parsed Decimal can be from 0. Adding or comparing Decimals
+// lines up their scales with a power of 10 and past this those get too big to work with.
+const maxDecimalScale = 100000
+
+// ParseDecimal parses a json number, e.g. "-12.50" or "1e-3".
+// Numbers whose scale would be more than 100000 away from 0, like 1e100001, are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	if !isNumberLiteral(s) {
+		return Decimal{}, fmt.Errorf("%q is not a valid json number", s)
+	}
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exponent, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("The exponent of %q is too big", s)
+		}
+	}
+	scale := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	if int64(scale)-int64(exponent) > maxDecimalScale || int64(scale)-int64(exponent) < -maxDecimalScale {
+		return Decimal{}, fmt.Errorf("The exponent of %q is too big", s)
+	}
+	coefficient, _ := new(big.Int).SetString(mantissa, 10)
+	return Decimal{coefficient: coefficient, scale: scale - exponent, literal: s}, nil
+}
+
+// Coefficient returns a copy of the coefficient
+func (d Decimal) Coefficient() *big.Int {
+	return new(big.Int).Set(d.coef())
+}
+
+// Scale returns the number of digits after the decimal point, which is negative for multiples of 10
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	return d.coef().Sign()
+}
+
+// String returns the json literal the Decimal was parsed from or, once it has been changed,
+// the digits with scale of them after the decimal point e.g. 12.50
+func (d Decimal) String() string {
+	if d.literal != "" {
+		return d.literal
+	}
+	coefficient := d.coef()
+	digits := new(big.Int).Abs(coefficient).String()
+	sign := ""
+	if coefficient.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		if d.scale == 0 || coefficient.Sign() == 0 {
+			return sign + digits
+		}
+		return sign + digits + "e" + strconv.Itoa(-d.scale)
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{coefficient: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{coefficient: a.Sub(a, b), scale: scale}
+}
+
+// Mul returns d * other
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coefficient: new(big.Int).Mul(d.coef(), other.coef()), scale: d.scale + other.scale}
+}
+
+// Cmp returns -1 if d < other, 0 if they are equal and 1 if d > other. 1.5 and 1.50 are equal.
+func (d Decimal) Cmp(other Decimal) int {
+	// the signs and then the number of digits before the point decide most comparisons without
+	// lining up the scales, which for 1e100000 and 1 would take a very big power of 10
+	sign := d.Sign()
+	switch {
+	case sign < other.Sign():
+		return -1
+	case sign > other.Sign():
+		return 1
+	case sign == 0:
+		return 0
+	}
+	if magnitude, otherMagnitude := d.magnitude(), other.magnitude(); magnitude != otherMagnitude {
+		if (magnitude > otherMagnitude) == (sign > 0) {
+			return 1
+		}
+		return -1
+	}
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+// Round returns d with exactly places digits after the decimal point, rounding halves away from zero.
+// So 2.345 rounded to 2 places is 2.35, -2.345 is -2.35 and 2.3 is 2.30.
+func (d Decimal) Round(places int) Decimal {
+	if d.scale <= places {
+		coefficient := new(big.Int).Mul(d.coef(), pow10(places-d.scale))
+		return Decimal{coefficient: coefficient, scale: places}
+	}
+	divisor := pow10(d.scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.coef(), divisor, new(big.Int))
+	// a remainder of at least half the divisor rounds away from zero
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		if d.coef().Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return Decimal{coefficient: quotient, scale: places}
+}
+
+func (d Decimal) coef() *big.Int {
+	if d.coefficient == nil {
+		return new(big.Int)
+	}
+	return d.coefficient
+}
+
+// magnitude is the number of digits before the decimal point, so 1 for 1.5 and -2 for 0.0015.
+// With the same magnitude the scales differ by no more than the number of digits in the coefficients.
+func (d Decimal) magnitude() int {
+	return len(new(big.Int).Abs(d.coef()).String()) - d.scale
+}
+
+// align returns new coefficients of a and b that have the same scale, and that scale
+func align(a Decimal, b Decimal) (*big.Int, *big.Int, int) {
+	x, y := new(big.Int).Set(a.coef()), new(big.Int).Set(b.coef())
+	switch {
+	case a.scale < b.scale:
+		x.Mul(x, pow10(b.scale-a.scale))
+		return x, y, b.scale
+	case a.scale > b.scale:
+		y.Mul(y, pow10(a.scale-b.scale))
+	}
+	return x, y, a.scale
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,124 @@
+package json
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		input       string
+		coefficient string
+		scale       int
+	}{
+		{"0", "0", 0},
+		{"12.50", "1250", 2},
+		{"-0.001", "-1", 3},
+		{"1e3", "1", -3},
+		{"1.5E-2", "15", 3},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890123456789", 9},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.input,
+			func(t *testing.T) {
+				d, err := ParseDecimal(testcase.input)
+				assert.Nil(err)
+				assert.Equal(testcase.coefficient, d.Coefficient().String())
+				assert.Equal(testcase.scale, d.Scale())
+				assert.Equal(testcase.input, d.String())
+			},
+		)
+	}
+	for _, input := range []string{"", "1.", "+1", "01", "1e", "abc", "1 ", "1e99999999999999999999", "1e100001", "1.5e-100000"} {
+		_, err := ParseDecimal(input)
+		assert.Error(err, input)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	a, b := mustParseDecimal("0.1"), mustParseDecimal("0.2")
+	assert.Equal("0.3", a.Add(b).String())
+	assert.Equal("-0.1", a.Sub(b).String())
+	assert.Equal("0.02", a.Mul(b).String())
+	assert.Equal("100.5", mustParseDecimal("1e2").Add(mustParseDecimal("0.5")).String())
+	assert.Equal("3e3", mustParseDecimal("1.5e3").Mul(mustParseDecimal("2")).Round(-3).String())
+	assert.Equal("0", Decimal{}.Add(Decimal{}).String())
+	assert.Equal("12.345", NewDecimal(big.NewInt(12345), 3).String())
+
+	assert.Equal(0, mustParseDecimal("1.5").Cmp(mustParseDecimal("1.50")))
+	assert.Equal(-1, mustParseDecimal("-2").Cmp(mustParseDecimal("1e-9")))
+	assert.Equal(1, mustParseDecimal("1e3").Cmp(mustParseDecimal("999.999")))
+	assert.Equal(0, Decimal{}.Cmp(mustParseDecimal("0.00")))
+	assert.Equal(-1, mustParseDecimal("-0.5").Sign())
+	// these don't need the scales lined up, which would take a power of 10 with 100 million digits
+	huge := NewDecimal(big.NewInt(1), -100000000)
+	assert.Equal(1, huge.Cmp(mustParseDecimal("1")))
+	assert.Equal(1, huge.Cmp(mustParseDecimal("-1e100000").Mul(mustParseDecimal("-1e100000"))))
+	assert.Equal(-1, huge.Cmp(NewDecimal(big.NewInt(2), -100000000)))
+	assert.Equal(-1, mustParseDecimal("-1e-100000").Cmp(NewDecimal(big.NewInt(-1), 100000000)))
+	assert.Equal(-1, mustParseDecimal("-1e100000").Cmp(mustParseDecimal("1e-100000")))
+
+	roundCases := map[string]string{"2.345": "2.35", "-2.345": "-2.35", "2.344": "2.34", "2.3": "2.30", "0.005": "0.01", "-0.004": "0.00", "999.999": "1000.00"}
+	for input, expected := range roundCases {
+		assert.Equal(expected, mustParseDecimal(input).Round(2).String(), input)
+	}
+	assert.Equal("1e2", mustParseDecimal("149.99").Round(-2).String())
+}
+
+func TestDecimalNumbers(t *testing.T) {
+	assert := assert.New(t)
+	input := `{"amount":0.10,"total":1.000e2,"count":3,"items":[19.99,-0.01]}`
+	opts := DecodeOptions{Numbers: DecimalNumbers, OrderedObjects: true}
+	value, err := opts.Decode([]byte(input))
+	if !assert.Nil(err) {
+		return
+	}
+	amount, _ := value.(*OrderedObject).Get("amount")
+	assert.IsType(Decimal{}, amount)
+
+	output, err := Marshall(value)
+	assert.Nil(err)
+	assert.Equal(input, string(output))
+
+	// the numbers Lenient allows are fine too
+	value, err = DecodeOptions{Numbers: DecimalNumbers, Lenient: true}.Decode([]byte(`[+1, 0123, -007.50, 00]`))
+	if assert.Nil(err) {
+		assert.Equal([]any{mustParseDecimal("1"), mustParseDecimal("123"), mustParseDecimal("-7.50"), mustParseDecimal("0")}, value)
+	}
+
+	// the exponent is far too big to work with
+	_, err = opts.Decode([]byte(`[1e100000000, 1]`))
+	assert.True(errors.Is(err, ErrInvalidNumber))
+
+	var invoice struct {
+		Amount Decimal
+		Total  Decimal
+		Count  int
+		Items  []Decimal
+	}
+	assert.Nil(opts.UnmarshallInto([]byte(input), &invoice))
+	assert.Equal("0.10", invoice.Amount.String())
+	assert.Equal(3, invoice.Count)
+	assert.Equal("19.98", invoice.Items[0].Add(invoice.Items[1]).String())
+	// the default numbers can go into a Decimal too
+	assert.Nil(UnmarshallInto([]byte(`{"Amount": 0.5}`), &invoice))
+	assert.Equal("0.5", invoice.Amount.String())
+
+	output, err = Marshall(invoice)
+	assert.Nil(err)
+	assert.Equal(`{"Amount":0.5,"Total":1.000e2,"Count":3,"Items":[19.99,-0.01]}`, string(output))
+}
@@ -0,0 +1,71 @@
+package json
+
+import (
+	"io"
+)
+
+// Decoder reads json values one after the other from a stream.
+// It only keeps a small window of the stream in memory, so the stream never has to be read in fully.
+type Decoder struct {
+	iter    *iterator
+	options *DecodeOptions
+	// started is true once the first value has been decoded
+	started bool
+	// err is the first error Decode returned, after which it can't tell where the next value starts
+	err error
+}
+
+// NewDecoder returns a Decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return DecodeOptions{}.NewDecoder(r)
+}
+
+// NewDecoder returns a Decoder that reads from r using these options
+func (opts DecodeOptions) NewDecoder(r io.Reader) *Decoder {
+	iter := newStreamIterator(r)
+	iter.limit = opts.MaxInputSize
+	return &Decoder{iter: iter, options: &opts}
+}
+
+// Decode reads the next json value from the stream. Values need to be separated by whitespace.
+// It returns io.EOF when there are no more values, the error from the reader if reading fails
+// and a *SyntaxError if the next value is not valid json. Once it returns an error it keeps returning it.
+func (dec *Decoder) Decode() (any, error) {
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	value, err := dec.decode()
+	if err != nil && err != io.EOF {
+		dec.err = err
+	}
+	return value, err
+}
+
+func (dec *Decoder) decode() (value any, err error) {
+	iter := dec.iter
+	if dec.started && iter.HasNext() && !isSpace(iter.Current()) {
+		return nil, &SyntaxError{newValidationError(iter, ErrUnexpectedCharacter, iter.Cursor(), "whitespace", "Values in a stream need to be separated by whitespace")}
+	}
+	iter.AdvancePastAllWhiteSpace()
+	if !iter.HasNext() {
+		if iter.err != nil && iter.err != io.EOF {
+			return nil, iter.err
+		}
+		if err := checkInputSize(iter, dec.options); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	dec.started = true
+
+	p := newParser(iter, dec.options.builder(), dec.options)
+	value, err = p.parseValue()
+	if err != nil {
+		// the value is cut short if the reader fails so that error is more useful than the syntax error
+		if iter.err != nil && iter.err != io.EOF {
+			return nil, iter.err
+		}
+		return nil, decodeError(err)
+	}
+	return value, nil
+}
@@ -0,0 +1,103 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	assert := assert.New(t)
+	input := " {\"k1\": [\"v1\", 12, 1.5, \"\"]}\n\"a long string " + strings.Repeat("é", streamChunkSize) + "\"\n123 true\tnull []  "
+	expected := []any{
+		map[string]any{"k1": []any{"v1", int64(12), 1.5, ""}},
+		"a long string " + strings.Repeat("é", streamChunkSize),
+		int64(123),
+		true,
+		nil,
+		make([]any, 0),
+	}
+	readers := map[string]io.Reader{
+		"all at once":   strings.NewReader(input),
+		"byte by byte":  iotest.OneByteReader(strings.NewReader(input)),
+		"with data err": iotest.DataErrReader(strings.NewReader(input)),
+	}
+	for name, reader := range readers {
+		t.Run(
+			name,
+			func(t *testing.T) {
+				dec := NewDecoder(reader)
+				for _, expectedValue := range expected {
+					value, err := dec.Decode()
+					assert.Nil(err)
+					assert.Equal(expectedValue, value)
+				}
+				_, err := dec.Decode()
+				assert.Equal(io.EOF, err)
+				_, err = dec.Decode()
+				assert.Equal(io.EOF, err)
+			},
+		)
+	}
+}
+
+func TestDecoderKeepsTheWindowSmall(t *testing.T) {
+	assert := assert.New(t)
+	input := "[" + strings.Repeat(`"abc", 1234, true, `, 10000) + "null]"
+	dec := NewDecoder(iotest.HalfReader(strings.NewReader(input)))
+	value, err := dec.Decode()
+	assert.Nil(err)
+	assert.Len(value, 30001)
+	assert.True(cap(dec.iter.s) <= 2*streamChunkSize, "The window grew to %d", cap(dec.iter.s))
+}
+
+func TestDecoderErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	dec := NewDecoder(strings.NewReader("[1,\n 2,\n x]"))
+	_, err := dec.Decode()
+	syntaxErr, ok := err.(*SyntaxError)
+	if assert.True(ok) {
+		assert.Equal(9, syntaxErr.Offset)
+	}
+
+	dec = NewDecoder(strings.NewReader(`[1][2]`))
+	_, err = dec.Decode()
+	assert.Nil(err)
+	_, err = dec.Decode()
+	assert.IsType(&SyntaxError{}, err)
+
+	// after an error in the middle of a value it can't tell where the next one starts
+	dec = NewDecoder(strings.NewReader("[1, x] 2"))
+	_, err = dec.Decode()
+	assert.IsType(&SyntaxError{}, err)
+	_, again := dec.Decode()
+	assert.Equal(err, again)
+
+	readErr := errors.New("connection reset")
+	dec = NewDecoder(io.MultiReader(strings.NewReader(`1 ["v1", `), iotest.ErrReader(readErr)))
+	value, err := dec.Decode()
+	assert.Nil(err)
+	assert.Equal(int64(1), value)
+	_, err = dec.Decode()
+	assert.Equal(readErr, err)
+	_, err = dec.Decode()
+	assert.Equal(readErr, err)
+}
+
+func TestStreamPosition(t *testing.T) {
+	assert := assert.New(t)
+	input := strings.Repeat("\"é\",\n", 2000) + `  "x" 1`
+	iter := newStreamIterator(iotest.OneByteReader(strings.NewReader(input)))
+	for iter.HasNext() && iter.Current() != 'x' {
+		iter.Mark()
+		iter.Next()
+	}
+	line, column := iter.Position(iter.Cursor())
+	assert.Equal(2001, line)
+	assert.Equal(4, column)
+}
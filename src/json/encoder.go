@@ -0,0 +1,47 @@
+package json
+
+import (
+	"io"
+)
+
+// Encoder writes json values one after the other to a stream.
+// It writes as it goes, so only a small buffer is kept in memory however big the values are.
+type Encoder struct {
+	enc *encoder
+}
+
+// NewEncoder returns an Encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return MarshallOptions{}.NewEncoder(w)
+}
+
+// NewEncoder returns an Encoder that writes to w using these options
+func (opts MarshallOptions) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: &encoder{options: &opts, writer: w}}
+}
+
+// Encode writes v the way Marshall would, followed by a new line if the options have Newline set.
+// Everything is written to the stream by the time it returns. If the stream returns an error, Encode
+// returns it and so does every call after it. If v can't be marshalled, Encode returns the same error
+// Marshall would. Nothing of v is written if it is small enough to fit in the buffer, otherwise the start
+// of it may have been written already, which leaves the stream broken so every later call returns the error too.
+func (e *Encoder) Encode(v any) error {
+	enc := e.enc
+	if enc.err != nil {
+		return enc.err
+	}
+	enc.depth, enc.nesting, enc.seen = 0, 0, nil
+	written := enc.written
+	err := marshall(enc, v)
+	if err != nil {
+		enc.Reset()
+		if enc.written > written {
+			enc.err = err
+		}
+		return err
+	}
+	if enc.options.Newline {
+		enc.WriteByte('\n')
+	}
+	return enc.flush()
+}
@@ -0,0 +1,120 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder(t *testing.T) {
+	assert := assert.New(t)
+	values := []any{
+		map[string]any{"k1": []any{"v1", int64(12), 1.5, ""}},
+		"a long string " + strings.Repeat("é", streamChunkSize),
+		int64(123),
+		true,
+		nil,
+		[]any{},
+	}
+
+	var output bytes.Buffer
+	enc := MarshallOptions{Newline: true}.NewEncoder(&output)
+	var expected []string
+	for _, value := range values {
+		assert.Nil(enc.Encode(value))
+		marshalled, err := Marshall(value)
+		assert.Nil(err)
+		expected = append(expected, string(marshalled)+"\n")
+	}
+	assert.Equal(strings.Join(expected, ""), output.String())
+
+	// it can be read back with a Decoder
+	dec := NewDecoder(&output)
+	for _, value := range values {
+		decoded, err := dec.Decode()
+		assert.Nil(err)
+		assert.Equal(value, decoded)
+	}
+	_, err := dec.Decode()
+	assert.Equal(io.EOF, err)
+
+	// without Newline the values run into each other
+	output.Reset()
+	enc = NewEncoder(&output)
+	assert.Nil(enc.Encode(int64(1)))
+	assert.Nil(enc.Encode([]any{"a"}))
+	assert.Equal(`1["a"]`, output.String())
+
+	// a value that can't be marshalled doesn't stop the next one
+	output.Reset()
+	assert.IsType(&UnsupportedValueError{}, enc.Encode([]any{int64(1), math.NaN()}))
+	assert.Nil(enc.Encode("next"))
+	assert.Equal(`"next"`, output.String())
+
+	// unless some of it has been written, then the stream is broken and stays that way
+	output.Reset()
+	big := make([]any, 3000)
+	for i := range big {
+		big[i] = "a string to fill up the buffer"
+	}
+	big = append(big, func() {})
+	err = enc.Encode(big)
+	assert.IsType(&UnsupportedTypeError{}, err)
+	assert.True(output.Len() > streamChunkSize)
+	written := output.Len()
+	assert.Equal(err, enc.Encode(int64(1)))
+	assert.Equal(written, output.Len())
+}
+
+// writeRecorder keeps the size of each write and fails once it has been given more than failAfter bytes
+type writeRecorder struct {
+	writes    []int
+	written   int
+	failAfter int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	w.written += len(p)
+	if w.failAfter > 0 && w.written > w.failAfter {
+		return 0, errWriteFailed
+	}
+	return len(p), nil
+}
+
+func TestEncoderWritesAsItGoes(t *testing.T) {
+	assert := assert.New(t)
+	type row struct {
+		ID   int      `json:"id"`
+		Tags []string `json:"tags"`
+	}
+	rows := make([]row, 10000)
+	for i := range rows {
+		rows[i] = row{ID: i, Tags: []string{"a", "b"}}
+	}
+	expected, err := Marshall(rows)
+	assert.Nil(err)
+
+	recorder := &writeRecorder{}
+	assert.Nil(NewEncoder(recorder).Encode(rows))
+	assert.Equal(len(expected), recorder.written)
+	assert.True(len(recorder.writes) > 1)
+	for _, size := range recorder.writes {
+		// the buffer is written out once it is full, before the next value
+		assert.True(size < streamChunkSize+64, size)
+	}
+
+	recorder = &writeRecorder{failAfter: 3 * streamChunkSize}
+	enc := NewEncoder(recorder)
+	assert.Equal(errWriteFailed, enc.Encode(rows))
+	// it stopped when the write failed instead of carrying on to the end
+	assert.True(recorder.written < 5*streamChunkSize)
+	assert.Equal(errWriteFailed, enc.Encode("more"))
+}
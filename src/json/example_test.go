@@ -11,3 +11,12 @@ func ExampleUnmarshall() {
 	// Output:
 	// [v1 v2]
 }
+
+// ExampleDecode demonstrates how to get an error instead of a panic for invalid json
+func ExampleDecode() {
+	_, err := Decode([]byte(`["v1" "v2"]`))
+	_, ok := err.(*SyntaxError)
+	fmt.Println(ok)
+	// Output:
+	// true
+}
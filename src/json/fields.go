@@ -0,0 +1,181 @@
+package json
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// field is what we know about a struct field that maps to a key in a json object
+type field struct {
+	name      string
+	goName    string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+// parseTag splits a `json:"name,omitempty,string"` tag into the name and the options
+func parseTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isScalarKind reports if the `string` tag option makes sense for a field of this kind
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// fieldCache maps a reflect.Type to its []field so we only work them out once per type
+var fieldCache sync.Map
+
+func cachedTypeFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]field)
+}
+
+// typeFields returns the fields of the struct type t that can be read from or written to json.
+// Fields of embedded structs are promoted, the way Go promotes them: a field that is less deeply
+// nested hides the others with the same name and if there is more than one at the same depth then
+// the one with a json tag wins. If that doesn't settle it, none of them is used.
+func typeFields(t reflect.Type) []field {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []field
+	taken := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+	next := []embedded{{typ: t}}
+	// breadth first, so that we see the less deeply nested fields first
+	for len(next) > 0 {
+		current := next
+		next = nil
+		atThisDepth := map[string][]field{}
+		var names []string
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				structField := e.typ.Field(i)
+				fieldType := structField.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+				isExported := structField.PkgPath == ""
+				if !isExported && !(structField.Anonymous && fieldType.Kind() == reflect.Struct) {
+					continue
+				}
+				tag := structField.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := parseTag(tag)
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if structField.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: fieldType, index: index})
+					continue
+				}
+				if !isExported {
+					continue
+				}
+				f := field{
+					name:      name,
+					goName:    structField.Name,
+					index:     index,
+					typ:       structField.Type,
+					tagged:    name != "",
+					omitEmpty: hasOption(options, "omitempty"),
+					quoted:    hasOption(options, "string") && isScalarKind(fieldType.Kind()),
+				}
+				if f.name == "" {
+					f.name = structField.Name
+				}
+				if _, ok := atThisDepth[f.name]; !ok {
+					names = append(names, f.name)
+				}
+				atThisDepth[f.name] = append(atThisDepth[f.name], f)
+			}
+		}
+		for _, name := range names {
+			if taken[name] {
+				continue
+			}
+			taken[name] = true
+			if dominant, ok := dominantField(atThisDepth[name]); ok {
+				fields = append(fields, dominant)
+			}
+		}
+	}
+	// fields are written out in the order they are declared in, promoted fields where their struct is embedded
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].index, fields[j].index)
+	})
+	return fields
+}
+
+func dominantField(fields []field) (field, bool) {
+	if len(fields) == 1 {
+		return fields[0], true
+	}
+	var tagged []field
+	for _, f := range fields {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return field{}, false
+}
+
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// lookupField finds the field for a key, falling back to a case-insensitive match
+func lookupField(fields []field, key string) *field {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package json
+
+import (
+	"bytes"
+	"strings"
+)
+
+// HintCode says which common mistake a Hint is about
+type HintCode string
+
+// These are the mistakes there are hints for
+const (
+	MissingComma       HintCode = "missing-comma"
+	TrailingComma      HintCode = "trailing-comma"
+	SingleQuotes       HintCode = "single-quotes"
+	UnquotedKey        HintCode = "unquoted-key"
+	Comment            HintCode = "comment"
+	PythonLiteral      HintCode = "python-literal"
+	UnterminatedString HintCode = "unterminated-string"
+)
+
+// Hint is a suggestion for how to fix a ValidationError that looks like a common mistake
+type Hint struct {
+	Code    HintCode
+	Message string
+	Fix     Fix
+}
+
+// Fix is an edit to the input: replace the bytes from Start up to End with Replacement.
+// Start and End are offsets like ValidationError.Offset and are the same to insert something.
+type Fix struct {
+	Start       int
+	End         int
+	Replacement string
+}
+
+// withHint adds a Hint to err if it looks like one of the common mistakes
+func withHint(iter *iterator, err error) error {
+	validationErr, ok := err.(ValidationError)
+	if !ok || validationErr.Hint != nil {
+		return err
+	}
+	hint := findHint(iter.s, iter.offset, validationErr)
+	if hint == nil {
+		return err
+	}
+	validationErr.Hint = hint
+	validationErr.msg += ". " + hint.Message
+	return validationErr
+}
+
+// findHint looks at the input around the error. s is all of the input we still have, from the position base.
+func findHint(s []byte, base int, err ValidationError) *Hint {
+	i := err.Offset - base
+	if i < 0 || i > len(s) {
+		return nil
+	}
+	var at byte
+	if i < len(s) {
+		at = s[i]
+	}
+	word := identifierAt(s, i)
+	switch {
+	case at == '/' && i+1 < len(s) && (s[i+1] == '/' || s[i+1] == '*'):
+		end := len(s)
+		if s[i+1] == '/' {
+			if newline := bytes.IndexByte(s[i:], '\n'); newline >= 0 {
+				end = i + newline
+			}
+		} else if close := bytes.Index(s[i+2:], []byte("*/")); close >= 0 {
+			end = i + 2 + close + 2
+		}
+		return &Hint{Comment, "Json doesn't allow comments, remove it", Fix{err.Offset, base + end, ""}}
+	case at == '\'' && (err.Expected == "a value" || err.Expected == "a string key"):
+		end := i + 1
+		for end < len(s) && s[end] != '\n' && (s[end] != '\'' || s[end-1] == '\\') {
+			end++
+		}
+		if end == len(s) || s[end] != '\'' {
+			return &Hint{SingleQuotes, "Strings need double quotes", Fix{err.Offset, err.Offset + 1, `"`}}
+		}
+		content := strings.NewReplacer(`"`, `\"`, `\'`, `'`).Replace(string(s[i+1 : end]))
+		return &Hint{SingleQuotes, "Strings need double quotes", Fix{err.Offset, base + end + 1, `"` + content + `"`}}
+	case err.Expected == "a value" && (word == "True" || word == "False" || word == "None"):
+		replacement := map[string]string{"True": "true", "False": "false", "None": "null"}[word]
+		return &Hint{PythonLiteral, "Json uses " + replacement + " instead of " + word, Fix{err.Offset, err.Offset + len(word), replacement}}
+	case err.Expected == "a string key" && word != "":
+		return &Hint{UnquotedKey, "Keys need to be in double quotes", Fix{err.Offset, err.Offset + len(word), `"` + word + `"`}}
+	case (at == ']' && err.Expected == "a value") || (at == '}' && err.Expected == "a string key"):
+		before := lastNonSpace(s, i)
+		if before < 0 || s[before] != ',' {
+			return nil
+		}
+		return &Hint{TrailingComma, "Json doesn't allow a comma after the last value, remove it", Fix{base + before, base + before + 1, ""}}
+	case err.Expected == "','" && isValueStart(at):
+		after := lastNonSpace(s, i) + 1
+		return &Hint{MissingComma, "There might be a comma missing", Fix{base + after, base + after, ","}}
+	case err.Rule == ruleString && err.Found == "end of input", err.Expected == "an escaped control character" && (at == '\n' || at == '\r'):
+		return &Hint{UnterminatedString, `The string isn't closed, it needs a " at the end`, Fix{err.Offset, err.Offset, `"`}}
+	}
+	return nil
+}
+
+// identifierAt returns the letters, digits, _ and $ that start at s[i] if it starts with a letter, _ or $
+func identifierAt(s []byte, i int) string {
+	end := i
+	for end < len(s) {
+		char := s[end]
+		isLetter := 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_' || char == '$'
+		if !isLetter && !(end > i && isDigit(char)) {
+			break
+		}
+		end++
+	}
+	return string(s[i:end])
+}
+
+// lastNonSpace returns the index of the last byte before s[i] that isn't whitespace, or -1
+func lastNonSpace(s []byte, i int) int {
+	for i--; i >= 0 && isSpace(s[i]); i-- {
+	}
+	return i
+}
+
+func isValueStart(char byte) bool {
+	switch char {
+	case '"', '[', '{', 't', 'f', 'n', '-', '\'':
+		return true
+	}
+	return isDigit(char)
+}
@@ -0,0 +1,64 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHints(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name  string
+		input string
+		code  HintCode
+		fixed string
+	}{
+		{"missing comma in an array", `[1, 2 3]`, MissingComma, `[1, 2, 3]`},
+		{"missing comma in an object", "{\"a\": 1\n \"b\": 2}", MissingComma, "{\"a\": 1,\n \"b\": 2}"},
+		{"trailing comma in an array", `[1, 2, ]`, TrailingComma, `[1, 2 ]`},
+		{"trailing comma in an object", `{"a": 1,}`, TrailingComma, `{"a": 1}`},
+		{"single quoted value", `{"a": 'it\'s "x"'}`, SingleQuotes, `{"a": "it's \"x\""}`},
+		{"single quoted key", `{'a': 1}`, SingleQuotes, `{"a": 1}`},
+		{"unquoted key", `{a_1: 1}`, UnquotedKey, `{"a_1": 1}`},
+		{"line comment", "[1, // one\n 2]", Comment, "[1, \n 2]"},
+		{"block comment", `{"a": /* one */ 1}`, Comment, `{"a":  1}`},
+		{"comment at the end", "[1]\n// done", Comment, "[1]\n"},
+		{"Python True", `[True]`, PythonLiteral, `[true]`},
+		{"Python None", `{"a": None}`, PythonLiteral, `{"a": null}`},
+		{"unterminated string", `["abc`, UnterminatedString, `["abc"`},
+		{"unterminated string before a newline", "[\"abc\n]", UnterminatedString, "[\"abc\"\n]"},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err, ok := Validate([]byte(testcase.input)).(ValidationError)
+				if !assert.True(ok) || !assert.NotNil(err.Hint) {
+					return
+				}
+				assert.Equal(testcase.code, err.Hint.Code)
+				assert.Contains(err.Error(), err.Hint.Message)
+				fix := err.Hint.Fix
+				fixed := testcase.input[:fix.Start] + fix.Replacement + testcase.input[fix.End:]
+				assert.Equal(testcase.fixed, fixed)
+			},
+		)
+	}
+
+	// no hint if it doesn't look like one of them
+	for _, input := range []string{`[1, }`, `{1: 2}`, `[1 :]`, `[Yes]`, `{"a" 1}`} {
+		err, ok := Validate([]byte(input)).(ValidationError)
+		if assert.True(ok, input) {
+			assert.Nil(err.Hint, input)
+		}
+	}
+
+	// every problem ValidateAll finds has its hint
+	errs := ValidateAll([]byte(`{"a": [1 2], "b": True, c: 1,}`))
+	var codes []HintCode
+	for _, err := range errs {
+		codes = append(codes, err.Hint.Code)
+	}
+	assert.Equal([]HintCode{MissingComma, PythonLiteral, UnquotedKey, TrailingComma}, codes)
+}
@@ -1,47 +1,108 @@
 package json
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"unicode/utf8"
 )
 
 // this is an iterator that keeps track of the last read position of s in Offset.
 // s should never be changed since then the Offset and len won't make sense anymore
 // after calling s.Len() - s.len should equal len(s)
+//
+// If reader is set then s is a window into the stream that is refilled as the cursor reaches the end of it.
+// Positions (Cursor, Slice etc) are still offsets from the start of the stream but only the bytes from
+// the last Mark onwards can be sliced, everything before that may have been discarded to keep the window small.
 type iterator struct {
 	s      []byte
 	cursor int
 	len    int
+
+	reader io.Reader
+	// err is the error the reader returned, if any. io.EOF is just the end of the stream
+	err error
+	// offset is the position of s[0] in the stream
+	offset int
+	// mark is the position from which nothing is discarded, -1 if there isn't one
+	mark int
+	// lines and lineRunes describe the discarded bytes so Position still works:
+	// the number of newlines and the number of runes after the last of them
+	lines     int
+	lineRunes int
+	// limit is the most bytes to read from the stream, 0 if there isn't one. One more byte than
+	// that is read so it is clear that the stream is too long.
+	limit int
+}
+
+// the size of the window an iterator reads a stream into. It grows if a single token is longer than this
+const streamChunkSize = 4096
+
+func newStreamIterator(reader io.Reader) *iterator {
+	return &iterator{s: make([]byte, 0, streamChunkSize), reader: reader, mark: -1}
 }
 
 // Selectors
 
 func (iter *iterator) Cursor() int {
 	// this is just so it clear that cursor is readOnly.
-	return iter.cursor
+	return iter.offset + iter.cursor
 }
+
+// Current, HasNext and Peek are the only selectors that read more of a stream
 func (iter *iterator) Current() byte {
-	if iter.cursor < len(iter.s) {
+	if iter.cursor < len(iter.s) || iter.fill() {
 		return iter.s[iter.cursor]
 	}
 	return 0
 }
 
 func (iter *iterator) HasNext() bool {
-	return iter.cursor < len(iter.s)
+	return iter.cursor < len(iter.s) || iter.fill()
+}
+
+// Peek returns the byte n places after the cursor without moving it, or 0 if there isn't one
+func (iter *iterator) Peek(n int) byte {
+	for iter.cursor+n >= len(iter.s) {
+		if !iter.fill() {
+			return 0
+		}
+	}
+	return iter.s[iter.cursor+n]
+}
+
+// CurrentRune decodes the rune that starts at the cursor and returns it and its size in bytes.
+// Like utf8.DecodeRune, it returns (utf8.RuneError, 1) if the bytes there are not valid UTF-8.
+func (iter *iterator) CurrentRune() (rune, int) {
+	// this is only to make sure a stream has the whole rune in the window
+	iter.Peek(utf8.UTFMax - 1)
+	return utf8.DecodeRune(iter.s[iter.cursor:])
 }
 
 func (iter *iterator) Slice(start int, end int) []byte {
+	start -= iter.offset
+	end -= iter.offset
+	if start < 0 {
+		start = 0
+	}
 	if end > len(iter.s) {
 		end = len(iter.s)
 	}
 	return iter.s[start:end]
 }
 
+// SliceTillCursor returns everything from start, which should come from Mark, to the cursor.
+// It also clears the mark.
 func (iter *iterator) SliceTillCursor(start int) []byte {
-	return iter.s[start:iter.cursor]
+	iter.mark = -1
+	return iter.s[start-iter.offset : iter.cursor]
 }
 
 func (iter *iterator) Len() int {
+	if iter.reader != nil {
+		// for a stream, this is how much we've seen so far
+		return iter.offset + len(iter.s)
+	}
 	// is len(iter.s) cached?
 	if iter.len != 0 {
 		return iter.len
@@ -50,15 +111,94 @@ func (iter *iterator) Len() int {
 	return len(iter.s)
 }
 
+// Position returns the 1-based line and column of offset. The column counts runes, not bytes.
+func (iter *iterator) Position(offset int) (line int, column int) {
+	offset -= iter.offset
+	if offset > len(iter.s) {
+		offset = len(iter.s)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := iter.s[:offset]
+	lines := bytes.Count(before, []byte{'\n'})
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	if lineStart == 0 {
+		return iter.lines + 1, iter.lineRunes + utf8.RuneCount(before) + 1
+	}
+	return iter.lines + lines + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
+
 // Mutators
 
 func (iter *iterator) Next() {
 	// I could have called this Advance to be consistent wih AdvancePast etc
-	if iter.cursor < iter.Len() {
+	if iter.cursor < len(iter.s) {
 		iter.cursor++
 	}
 }
 
+// Mark returns the cursor, like Cursor, and makes sure that a stream keeps everything from it
+// so it can be passed to SliceTillCursor later
+func (iter *iterator) Mark() int {
+	iter.mark = iter.Cursor()
+	return iter.mark
+}
+
+// fill reads more of the stream into s. It returns false if there is nothing more to read.
+func (iter *iterator) fill() bool {
+	if iter.reader == nil || iter.err != nil {
+		return false
+	}
+	if iter.limit > 0 && iter.Len() > iter.limit {
+		return false
+	}
+	keep := iter.cursor
+	if iter.mark >= 0 && iter.mark-iter.offset < keep {
+		keep = iter.mark - iter.offset
+	}
+	if keep > 0 {
+		iter.discard(keep)
+	}
+	if len(iter.s) == cap(iter.s) {
+		grown := make([]byte, len(iter.s), 2*cap(iter.s)+streamChunkSize)
+		copy(grown, iter.s)
+		iter.s = grown
+	}
+	end := cap(iter.s)
+	if iter.limit > 0 && iter.offset+end > iter.limit+1 {
+		end = iter.limit + 1 - iter.offset
+	}
+	// like bufio, give up if the reader keeps returning nothing
+	for attempts := 0; attempts < 100; attempts++ {
+		n, err := iter.reader.Read(iter.s[len(iter.s):end])
+		iter.s = iter.s[:len(iter.s)+n]
+		if err != nil {
+			iter.err = err
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+	}
+	iter.err = io.ErrNoProgress
+	return false
+}
+
+// discard drops the first n bytes of the window
+func (iter *iterator) discard(n int) {
+	dropped := iter.s[:n]
+	if lastNewline := bytes.LastIndexByte(dropped, '\n'); lastNewline >= 0 {
+		iter.lines += bytes.Count(dropped, []byte{'\n'})
+		iter.lineRunes = utf8.RuneCount(dropped[lastNewline+1:])
+	} else {
+		iter.lineRunes += utf8.RuneCount(dropped)
+	}
+	iter.s = iter.s[:copy(iter.s, iter.s[n:])]
+	iter.cursor -= n
+	iter.offset += n
+}
+
 func (iter *iterator) AdvancePastAllWhiteSpace() {
 	for isSpace(iter.Current()) {
 		iter.Next()
@@ -71,10 +211,11 @@ func (iter *iterator) AdvancePast(char byte) error {
 		iter.Next()
 		return nil
 	}
+	expected := fmt.Sprintf("%q", char)
 	if iter.Current() == 0 {
-		return ValidationError{msg: fmt.Sprintf("Was expecting %q but we are at the end", char)}
+		return newValidationError(iter, ErrUnexpectedCharacter, iter.Cursor(), expected, "Was expecting %q but we are at the end", char)
 	}
-	return ValidationError{msg: fmt.Sprintf("Was expecting %q but got %q instead", char, iter.Current())}
+	return newValidationError(iter, ErrUnexpectedCharacter, iter.Cursor(), expected, "Was expecting %q but got %q instead", char, iter.Current())
 }
 
 func isSpace(ch byte) bool {
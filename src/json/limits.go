@@ -0,0 +1,135 @@
+package json
+
+import (
+	"fmt"
+)
+
+// Limit is one of the limits in DecodeOptions that stop untrusted input from using too much memory
+type Limit int
+
+// These are the limits a LimitError can be about
+const (
+	DepthLimit Limit = iota + 1
+	InputSizeLimit
+	StringLengthLimit
+	NumberLengthLimit
+	ArrayLengthLimit
+	ObjectKeysLimit
+	NodesLimit
+)
+
+var limitNames = map[Limit]string{
+	DepthLimit:        "MaxDepth",
+	InputSizeLimit:    "MaxInputSize",
+	StringLengthLimit: "MaxStringLength",
+	NumberLengthLimit: "MaxNumberLength",
+	ArrayLengthLimit:  "MaxArrayLength",
+	ObjectKeysLimit:   "MaxObjectKeys",
+	NodesLimit:        "MaxNodes",
+}
+
+// String returns the name of the DecodeOptions field for the limit
+func (limit Limit) String() string {
+	if name, ok := limitNames[limit]; ok {
+		return name
+	}
+	return fmt.Sprintf("Limit(%d)", int(limit))
+}
+
+// LimitError is returned instead of a ValidationError or SyntaxError when the json goes over one of the limits
+// in DecodeOptions. The json might be valid, it is just too big. The position is where the limit was reached.
+type LimitError struct {
+	ValidationError
+	Limit Limit
+	// Max is the value of the limit
+	Max int
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) true for all of them
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+func newLimitError(iter *iterator, offset int, limit Limit, max int, expected string, msg string) *LimitError {
+	kind := ErrLimitExceeded
+	if limit == DepthLimit {
+		kind = ErrDepthExceeded
+	}
+	return &LimitError{
+		ValidationError: newValidationError(iter, kind, offset, fmt.Sprintf("at most %d %s", max, expected), msg, max),
+		Limit:           limit,
+		Max:             max,
+	}
+}
+
+// checkInputSize is called before reading anything from a []byte, or at the end of a stream
+func checkInputSize(iter *iterator, options *DecodeOptions) error {
+	if options.MaxInputSize > 0 && iter.Len() > options.MaxInputSize {
+		return newLimitError(iter, options.MaxInputSize, InputSizeLimit, options.MaxInputSize, "bytes", "The input is longer than the limit of %d bytes")
+	}
+	return nil
+}
+
+// checkStreamSize is called after reading a value from a stream, err is the error from reading it if any.
+// The stream is cut short one byte after MaxInputSize, so if the value went past MaxInputSize or
+// reading it failed there then the problem is really the size of the stream.
+func checkStreamSize(iter *iterator, options *DecodeOptions, err error) error {
+	if iter.reader == nil || options.MaxInputSize <= 0 {
+		return nil
+	}
+	offset := -1
+	switch e := err.(type) {
+	case ValidationError:
+		offset = e.Offset
+	case *LimitError:
+		offset = e.Offset
+	}
+	if iter.Cursor() > options.MaxInputSize || offset >= options.MaxInputSize {
+		return newLimitError(iter, options.MaxInputSize, InputSizeLimit, options.MaxInputSize, "bytes", "The input is longer than the limit of %d bytes")
+	}
+	return nil
+}
+
+// checkDepth is called before opening an array or object inside depth others
+func checkDepth(iter *iterator, depth int, options *DecodeOptions) error {
+	maxDepth := options.maxDepth()
+	if maxDepth >= 0 && depth >= maxDepth {
+		return newLimitError(iter, iter.Cursor(), DepthLimit, maxDepth, "nested arrays and objects", "Arrays and objects can't be nested more than %d deep")
+	}
+	return nil
+}
+
+// checkLength is called before reading value number count+1 of an array or object
+func checkLength(iter *iterator, isObject bool, count int, options *DecodeOptions) error {
+	if isObject && options.MaxObjectKeys > 0 && count >= options.MaxObjectKeys {
+		return newLimitError(iter, iter.Cursor(), ObjectKeysLimit, options.MaxObjectKeys, "keys", "Objects can't have more than %d keys")
+	}
+	if !isObject && options.MaxArrayLength > 0 && count >= options.MaxArrayLength {
+		return newLimitError(iter, iter.Cursor(), ArrayLengthLimit, options.MaxArrayLength, "values", "Arrays can't have more than %d values")
+	}
+	return nil
+}
+
+// checkNumberLength is called while reading the number that started at start
+func checkNumberLength(iter *iterator, start int, options *DecodeOptions) error {
+	if options.MaxNumberLength > 0 && iter.Cursor()-start > options.MaxNumberLength {
+		return newLimitError(iter, start, NumberLengthLimit, options.MaxNumberLength, "characters", "Numbers can't be longer than %d characters")
+	}
+	return nil
+}
+
+// checkNodes is called before reading value number count+1 of the document
+func checkNodes(iter *iterator, count int, options *DecodeOptions) error {
+	if options.MaxNodes > 0 && count >= options.MaxNodes {
+		return newLimitError(iter, iter.Cursor(), NodesLimit, options.MaxNodes, "values", "The json can't have more than %d values in total")
+	}
+	return nil
+}
+
+// decodeError is the error Decode returns for an error from the parser
+func decodeError(err error) error {
+	if validationErr, ok := err.(ValidationError); ok {
+		return &SyntaxError{validationErr}
+	}
+	return err
+}
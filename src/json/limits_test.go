@@ -0,0 +1,115 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name    string
+		options DecodeOptions
+		input   string
+		limit   Limit
+		offset  int
+		path    string
+	}{
+		{"input size", DecodeOptions{MaxInputSize: 10}, `["abcdefgh"]`, InputSizeLimit, 10, ""},
+		{"string length", DecodeOptions{MaxStringLength: 3}, `["abc", "abcd"]`, StringLengthLimit, 8, "/1"},
+		{"key length", DecodeOptions{MaxStringLength: 3}, `{"abcd": 1}`, StringLengthLimit, 1, ""},
+		{"number length", DecodeOptions{MaxNumberLength: 4}, `{"a": [1.25, -1.25]}`, NumberLengthLimit, 13, "/a/1"},
+		{"array length", DecodeOptions{MaxArrayLength: 2}, `[[1, 2], [1, 2, 3]]`, ArrayLengthLimit, 16, "/1"},
+		{"object keys", DecodeOptions{MaxObjectKeys: 1}, `[{"a": 1}, {"a": 1, "b": 2}]`, ObjectKeysLimit, 20, "/1"},
+		{"nodes", DecodeOptions{MaxNodes: 4}, `{"a": [1, {"b": 2}]}`, NodesLimit, 16, "/a/1/b"},
+		{"depth", DecodeOptions{MaxDepth: 1}, `[[]]`, DepthLimit, 1, "/0"},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err := testcase.options.Validate([]byte(testcase.input))
+				limitErr, ok := err.(*LimitError)
+				if !assert.True(ok, "%v", err) {
+					return
+				}
+				assert.Equal(testcase.limit, limitErr.Limit)
+				assert.Equal(testcase.offset, limitErr.Offset)
+				assert.Equal(testcase.path, limitErr.Path)
+
+				_, decodeErr := testcase.options.Decode([]byte(testcase.input))
+				assert.Equal(err, decodeErr)
+				_, decodeErr = testcase.options.NewDecoder(iotest.OneByteReader(strings.NewReader(testcase.input))).Decode()
+				assert.Equal(err, decodeErr)
+
+				tokenizer := testcase.options.NewTokenizer([]byte(testcase.input))
+				var tokenizerErr error
+				for tokenizerErr == nil {
+					_, tokenizerErr = tokenizer.Next()
+				}
+				assert.Equal(err, tokenizerErr)
+
+				// one more and it is fine
+				assert.Nil(DecodeOptions{}.Validate([]byte(testcase.input)))
+			},
+		)
+	}
+	err := DecodeOptions{MaxStringLength: 3}.Validate([]byte(`"abcd"`))
+	assert.True(errors.Is(err, ErrLimitExceeded))
+	assert.False(errors.Is(err, ErrDepthExceeded))
+	assert.Nil(DecodeOptions{MaxInputSize: 12, MaxStringLength: 8, MaxNumberLength: 5, MaxArrayLength: 3, MaxObjectKeys: 2, MaxNodes: 9}.Validate([]byte(`["abcdefgh"]`)))
+}
+
+func TestDecoderInputSizeLimit(t *testing.T) {
+	assert := assert.New(t)
+	decoder := DecodeOptions{MaxInputSize: 8}.NewDecoder(strings.NewReader(`1 2 3 4 5 6`))
+	var values []any
+	var err error
+	for err == nil {
+		var value any
+		value, err = decoder.Decode()
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+	assert.Equal([]any{int64(1), int64(2), int64(3), int64(4)}, values)
+	limitErr, ok := err.(*LimitError)
+	if assert.True(ok) {
+		assert.Equal(InputSizeLimit, limitErr.Limit)
+		assert.Equal(8, limitErr.Offset)
+	}
+
+	// a stream that ends at the limit is fine
+	decoder = DecodeOptions{MaxInputSize: 3}.NewDecoder(strings.NewReader(`1 2`))
+	decoder.Decode()
+	decoder.Decode()
+	_, err = decoder.Decode()
+	assert.Equal(io.EOF, err)
+}
+
+// digits is a stream of 1s that never ends
+type digits struct{}
+
+func (digits) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = '1'
+	}
+	return len(p), nil
+}
+
+func TestDecoderNumberLengthLimit(t *testing.T) {
+	assert := assert.New(t)
+	// the error comes back once the number is too long rather than after all of it has been read in
+	decoder := DecodeOptions{MaxNumberLength: 10}.NewDecoder(io.LimitReader(digits{}, 50<<20))
+	_, err := decoder.Decode()
+	limitErr, ok := err.(*LimitError)
+	if assert.True(ok) {
+		assert.Equal(NumberLengthLimit, limitErr.Limit)
+	}
+	assert.True(cap(decoder.iter.s) <= streamChunkSize, cap(decoder.iter.s))
+}
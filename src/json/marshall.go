@@ -0,0 +1,715 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// UnsupportedTypeError is returned by Marshall when it is asked to encode a type that has no json representation
+type UnsupportedTypeError struct {
+	Value any
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("Cannot marshall a value of type %T", e.Value)
+}
+
+// UnsupportedValueError is returned by Marshall for values of a supported type that json can't represent, like NaN
+type UnsupportedValueError struct {
+	Value any
+	// Reason is set instead of printing Value when that wouldn't work, e.g. for a value that contains itself
+	Reason string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	if e.Reason != "" {
+		return "Cannot marshall the value, " + e.Reason
+	}
+	return fmt.Sprintf("Cannot marshall the value %v", e.Value)
+}
+
+// this is the counterpart of the iterator. The marshall functions append to it.
+type encoder struct {
+	bytes.Buffer
+	options *MarshallOptions
+	// depth is how many arrays and objects we are in, for the indentation
+	depth int
+	// scratch is reused to format numbers
+	scratch [64]byte
+	// writer is set for an Encoder, which writes the buffer to it whenever it fills up
+	writer io.Writer
+	// err is the error writer returned, once it returns one nothing more is written
+	err error
+	// written is the number of bytes given to writer
+	written int
+	// nesting is how many pointers, maps and slices deep we are and seen is the ones we are in, once
+	// nesting is more than startDetectingCyclesAfter
+	nesting int
+	seen    map[cycleKey]struct{}
+}
+
+// startDetectingCyclesAfter is how deep the marshall functions go before they look out for a value that contains
+// itself, which would go on until the stack overflows. Like encoding/json, it isn't worth the cost before that.
+const startDetectingCyclesAfter = 1000
+
+// cycleKey is what enter remembers about a pointer, map or slice. Slices of different lengths of the same array are
+// not the same value.
+type cycleKey struct {
+	ptr    uintptr
+	typ    reflect.Type
+	length int
+}
+
+// enter is called before marshalling what v, a pointer, map or slice, refers to and leave is called after.
+// Once we are deep enough, enter returns an error if we are already inside v.
+func (enc *encoder) enter(v reflect.Value) error {
+	if enc.nesting >= startDetectingCyclesAfter {
+		key := cycleKey{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			key.length = v.Len()
+		}
+		if _, ok := enc.seen[key]; ok {
+			return &UnsupportedValueError{Value: v.Interface(), Reason: fmt.Sprintf("it contains itself through a %s", v.Type())}
+		}
+		if enc.seen == nil {
+			enc.seen = map[cycleKey]struct{}{}
+		}
+		enc.seen[key] = struct{}{}
+	}
+	enc.nesting++
+	return nil
+}
+
+func (enc *encoder) leave(v reflect.Value) {
+	enc.nesting--
+	if enc.nesting >= startDetectingCyclesAfter {
+		key := cycleKey{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			key.length = v.Len()
+		}
+		delete(enc.seen, key)
+	}
+}
+
+// flush writes the buffer to the writer and empties it
+func (enc *encoder) flush() error {
+	if enc.err == nil && enc.Len() > 0 {
+		var n int
+		n, enc.err = enc.writer.Write(enc.Bytes())
+		enc.written += n
+	}
+	enc.Reset()
+	return enc.err
+}
+
+// open starts an array or object
+func (enc *encoder) open(char byte) {
+	enc.WriteByte(char)
+	enc.depth++
+}
+
+// item writes what goes before the ith value of an array or member of an object
+func (enc *encoder) item(i int) {
+	if i > 0 {
+		enc.WriteByte(',')
+	}
+	enc.newline()
+}
+
+// key writes the key of the ith member of an object and the : after it
+func (enc *encoder) key(i int, key string) {
+	enc.item(i)
+	marshallString(enc, key)
+	enc.WriteByte(':')
+	if enc.indented() {
+		enc.WriteByte(' ')
+	}
+}
+
+// close ends an array or object that had n values
+func (enc *encoder) close(char byte, n int) {
+	enc.depth--
+	if n > 0 {
+		enc.newline()
+	}
+	enc.WriteByte(char)
+}
+
+func (enc *encoder) indented() bool {
+	return enc.options.Prefix != "" || enc.options.Indent != ""
+}
+
+func (enc *encoder) newline() {
+	if !enc.indented() {
+		return
+	}
+	enc.WriteByte('\n')
+	enc.WriteString(enc.options.Prefix)
+	for i := 0; i < enc.depth; i++ {
+		enc.WriteString(enc.options.Indent)
+	}
+}
+
+// Marshall is used to dump an object to a json string.
+// It accepts the types Unmarshall produces:
+// map[string]any, *OrderedObject, []any, int64, float64, Number, *big.Int, *big.Float, Decimal, string, bool and nil.
+// Other Go values are encoded the way UnmarshallInto would read them back: structs (using the same
+// `json:"name,omitempty,string"` tags), slices, arrays, maps with string or integer keys, pointers
+// and the numeric kinds. Channels, functions and complex numbers can't be marshalled.
+// Floats are written like JavaScript writes them, with the fewest digits that read back as the same float,
+// so 3.0 is written as 3. That means Unmarshall reads the output back as the same value except that floats
+// that are whole numbers come back as int64. MarshallOptions.FloatSuffix writes them as 3.0 to keep them floats.
+func Marshall(v any) ([]byte, error) {
+	return MarshallOptions{}.Marshall(v)
+}
+
+// Marshall is Marshall using these options
+func (opts MarshallOptions) Marshall(v any) ([]byte, error) {
+	enc := &encoder{options: &opts}
+	err := marshall(enc, v)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Bytes(), nil
+}
+
+func marshall(enc *encoder, v any) error {
+	if enc.writer != nil && enc.Len() >= streamChunkSize {
+		if err := enc.flush(); err != nil {
+			return err
+		}
+	}
+	switch value := v.(type) {
+	case nil:
+		enc.WriteString("null")
+	case bool:
+		if value {
+			enc.WriteString("true")
+		} else {
+			enc.WriteString("false")
+		}
+	case string:
+		marshallString(enc, value)
+	case int:
+		enc.WriteString(strconv.Itoa(value))
+	case int64:
+		enc.WriteString(strconv.FormatInt(value, 10))
+	case float64:
+		return marshallFloat(enc, value, 64)
+	case []any:
+		return marshallArray(enc, value)
+	case map[string]any:
+		return marshallObject(enc, value)
+	case *OrderedObject:
+		return marshallOrderedObject(enc, value)
+	case Number:
+		return marshallNumber(enc, value)
+	case *big.Int:
+		return marshallBigInt(enc, value)
+	case *big.Float:
+		return marshallBigFloat(enc, value)
+	case Decimal:
+		enc.WriteString(value.String())
+	default:
+		return marshallValue(enc, reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// marshallValue is the slower path of marshall for the types that are not produced by Unmarshall
+func marshallValue(enc *encoder, v reflect.Value) error {
+	if enc.writer != nil && enc.Len() >= streamChunkSize {
+		if err := enc.flush(); err != nil {
+			return err
+		}
+	}
+	if v.IsValid() && v.CanInterface() {
+		switch v.Type() {
+		case orderedObjectType, numberType, bigIntType, bigFloatType, decimalType:
+			// these are written the same way marshall writes them
+			return marshall(enc, v.Interface())
+		}
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		enc.WriteString("null")
+	case reflect.Bool:
+		if v.Bool() {
+			enc.WriteString("true")
+		} else {
+			enc.WriteString("false")
+		}
+	case reflect.String:
+		marshallString(enc, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32:
+		return marshallFloat(enc, v.Float(), 32)
+	case reflect.Float64:
+		return marshallFloat(enc, v.Float(), 64)
+	case reflect.Interface:
+		if v.IsNil() {
+			enc.WriteString("null")
+			return nil
+		}
+		return marshallValue(enc, v.Elem())
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() && v.Kind() != reflect.Map {
+			// marshallMap checks the type of the keys first
+			enc.WriteString("null")
+			return nil
+		}
+		if err := enc.enter(v); err != nil {
+			return err
+		}
+		var err error
+		switch v.Kind() {
+		case reflect.Ptr:
+			err = marshallValue(enc, v.Elem())
+		case reflect.Slice:
+			err = marshallSequence(enc, v)
+		default:
+			err = marshallMap(enc, v)
+		}
+		if err != nil {
+			return err
+		}
+		enc.leave(v)
+	case reflect.Array:
+		return marshallSequence(enc, v)
+	case reflect.Struct:
+		return marshallStruct(enc, v)
+	default:
+		return &UnsupportedTypeError{Value: v.Interface()}
+	}
+	return nil
+}
+
+func marshallFloat(enc *encoder, value float64, bitSize int) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return &UnsupportedValueError{Value: value}
+	}
+	if !enc.options.FloatSuffix {
+		enc.Write(appendFloat(enc.scratch[:0], value, bitSize))
+		return nil
+	}
+	if value == 0 && math.Signbit(value) {
+		// ECMAScript writes -0 as 0 but we can keep the sign
+		enc.WriteByte('-')
+	}
+	start := enc.Len()
+	enc.Write(appendFloat(enc.scratch[:0], value, bitSize))
+	// make sure it is read back as a float and not as an int64
+	if !bytes.ContainsAny(enc.Bytes()[start:], ".e") {
+		enc.WriteString(".0")
+	}
+	return nil
+}
+
+// appendFloat appends value to b the way ECMAScript's Number.prototype.toString writes it: the fewest digits
+// that read back as the same float, with an exponent only if the value is less than 1e-6 or at least 1e21.
+func appendFloat(b []byte, value float64, bitSize int) []byte {
+	if value == 0 {
+		return append(b, '0')
+	}
+	if value < 0 {
+		b = append(b, '-')
+		value = -value
+	}
+	// this is d.ddde±x with the fewest digits, which we lay out again
+	scientific := strconv.AppendFloat(make([]byte, 0, 32), value, 'e', -1, bitSize)
+	e := bytes.IndexByte(scientific, 'e')
+	exponent, _ := strconv.Atoi(string(scientific[e+1:]))
+	// the digits without the point, moved down over it
+	digits := scientific[:1]
+	if e > 1 {
+		digits = append(digits, scientific[2:e]...)
+	}
+	// the value is 0.digits times 10 to the power n
+	k, n := len(digits), exponent+1
+	switch {
+	case k <= n && n <= 21:
+		b = append(b, digits...)
+		for i := k; i < n; i++ {
+			b = append(b, '0')
+		}
+	case 0 < n && n <= 21:
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:]...)
+	case -6 < n && n <= 0:
+		b = append(b, '0', '.')
+		for i := n; i < 0; i++ {
+			b = append(b, '0')
+		}
+		b = append(b, digits...)
+	default:
+		b = append(b, digits[0])
+		if k > 1 {
+			b = append(b, '.')
+			b = append(b, digits[1:]...)
+		}
+		b = append(b, 'e')
+		if n-1 > 0 {
+			b = append(b, '+')
+		}
+		b = strconv.AppendInt(b, int64(n-1), 10)
+	}
+	return b
+}
+
+// marshallNumber writes the literal as it is, as long as it is a valid json number
+func marshallNumber(enc *encoder, value Number) error {
+	if value == "" {
+		enc.WriteByte('0')
+		return nil
+	}
+	if !isNumberLiteral(string(value)) {
+		return &UnsupportedValueError{Value: value}
+	}
+	enc.WriteString(string(value))
+	return nil
+}
+
+func marshallBigInt(enc *encoder, value *big.Int) error {
+	if value == nil {
+		enc.WriteString("null")
+		return nil
+	}
+	enc.WriteString(value.String())
+	return nil
+}
+
+func marshallBigFloat(enc *encoder, value *big.Float) error {
+	if value == nil {
+		enc.WriteString("null")
+		return nil
+	}
+	if value.IsInf() {
+		return &UnsupportedValueError{Value: value}
+	}
+	start := enc.Len()
+	enc.WriteString(value.Text('g', -1))
+	// make sure it is read back as a float, like marshallFloat
+	if enc.options.FloatSuffix && !bytes.ContainsAny(enc.Bytes()[start:], ".eE") {
+		enc.WriteString(".0")
+	}
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+func marshallString(enc *encoder, s string) {
+	options := enc.options
+	enc.WriteByte('"')
+	// start is the beginning of the run of characters that don't need escaping
+	start := 0
+	for i := 0; i < len(s); {
+		char := s[i]
+		if char >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			escape := options.EscapeNonASCII || (options.EscapeLineSeparators && (r == '\u2028' || r == '\u2029'))
+			if r == utf8.RuneError && size == 1 {
+				enc.WriteString(s[start:i])
+				if escape {
+					enc.WriteString(`\ufffd`)
+				} else {
+					enc.WriteString("\ufffd")
+				}
+				i += size
+				start = i
+				continue
+			}
+			if escape {
+				enc.WriteString(s[start:i])
+				if r >= 0x10000 {
+					high, low := utf16.EncodeRune(r)
+					writeUnicodeEscape(enc, high)
+					writeUnicodeEscape(enc, low)
+				} else {
+					writeUnicodeEscape(enc, r)
+				}
+				start = i + size
+			}
+			i += size
+			continue
+		}
+		if char >= 0x20 && char != '"' && char != '\\' && !(options.EscapeHTML && (char == '<' || char == '>' || char == '&')) {
+			i++
+			continue
+		}
+		enc.WriteString(s[start:i])
+		switch char {
+		case '"', '\\':
+			enc.WriteByte('\\')
+			enc.WriteByte(char)
+		case '\n':
+			enc.WriteString(`\n`)
+		case '\r':
+			enc.WriteString(`\r`)
+		case '\t':
+			enc.WriteString(`\t`)
+		case '\b':
+			enc.WriteString(`\b`)
+		case '\f':
+			enc.WriteString(`\f`)
+		default:
+			writeUnicodeEscape(enc, rune(char))
+		}
+		i++
+		start = i
+	}
+	enc.WriteString(s[start:])
+	enc.WriteByte('"')
+}
+
+// writeUnicodeEscape writes r, which has to be less than 0x10000, as \uXXXX
+func writeUnicodeEscape(enc *encoder, r rune) {
+	enc.WriteString(`\u`)
+	enc.WriteByte(hex[r>>12&0xF])
+	enc.WriteByte(hex[r>>8&0xF])
+	enc.WriteByte(hex[r>>4&0xF])
+	enc.WriteByte(hex[r&0xF])
+}
+
+func marshallArray(enc *encoder, array []any) error {
+	if array == nil {
+		// the same as a nil slice or map of any other type
+		enc.WriteString("null")
+		return nil
+	}
+	if enc.nesting >= startDetectingCyclesAfter {
+		// this is deep enough to be a cycle, marshallValue looks out for them
+		return marshallValue(enc, reflect.ValueOf(array))
+	}
+	enc.nesting++
+	enc.open('[')
+	for i, item := range array {
+		enc.item(i)
+		err := marshall(enc, item)
+		if err != nil {
+			return err
+		}
+	}
+	enc.close(']', len(array))
+	enc.nesting--
+	return nil
+}
+
+func marshallObject(enc *encoder, object map[string]any) error {
+	if object == nil {
+		enc.WriteString("null")
+		return nil
+	}
+	if enc.nesting >= startDetectingCyclesAfter {
+		// this is deep enough to be a cycle, marshallValue looks out for them
+		return marshallValue(enc, reflect.ValueOf(object))
+	}
+	enc.nesting++
+	enc.open('{')
+	if enc.options.SortKeys {
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			enc.key(i, key)
+			err := marshall(enc, object[key])
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		i := 0
+		for key, value := range object {
+			enc.key(i, key)
+			i++
+			err := marshall(enc, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	enc.close('}', len(object))
+	enc.nesting--
+	return nil
+}
+
+var (
+	orderedObjectType = reflect.TypeOf((*OrderedObject)(nil))
+	numberType        = reflect.TypeOf(Number(""))
+	bigIntType        = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType      = reflect.TypeOf((*big.Float)(nil))
+	decimalType       = reflect.TypeOf(Decimal{})
+)
+
+func marshallOrderedObject(enc *encoder, object *OrderedObject) error {
+	if object == nil {
+		enc.WriteString("null")
+		return nil
+	}
+	if err := enc.enter(reflect.ValueOf(object)); err != nil {
+		return err
+	}
+	enc.open('{')
+	for i, key := range object.keys {
+		enc.key(i, key)
+		err := marshall(enc, object.values[key])
+		if err != nil {
+			return err
+		}
+	}
+	enc.close('}', len(object.keys))
+	enc.leave(reflect.ValueOf(object))
+	return nil
+}
+
+func marshallSequence(enc *encoder, v reflect.Value) error {
+	enc.open('[')
+	for i := 0; i < v.Len(); i++ {
+		enc.item(i)
+		err := marshallValue(enc, v.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+	enc.close(']', v.Len())
+	return nil
+}
+
+func marshallMap(enc *encoder, v reflect.Value) error {
+	if !isMapKeyKind(v.Type().Key().Kind()) {
+		return &UnsupportedTypeError{Value: v.Interface()}
+	}
+	if v.IsNil() {
+		enc.WriteString("null")
+		return nil
+	}
+	enc.open('{')
+	keys := make([]string, 0, v.Len())
+	values := make([]reflect.Value, 0, v.Len())
+	entries := v.MapRange()
+	for entries.Next() {
+		key := entries.Key()
+		switch key.Kind() {
+		case reflect.String:
+			keys = append(keys, key.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			keys = append(keys, strconv.FormatInt(key.Int(), 10))
+		default:
+			keys = append(keys, strconv.FormatUint(key.Uint(), 10))
+		}
+		values = append(values, entries.Value())
+	}
+	if enc.options.SortKeys {
+		sort.Sort(mapEntries{keys, values})
+	}
+	for i, key := range keys {
+		enc.key(i, key)
+		err := marshallValue(enc, values[i])
+		if err != nil {
+			return err
+		}
+	}
+	enc.close('}', len(keys))
+	return nil
+}
+
+// mapEntries sorts the keys of a map and its values with them
+type mapEntries struct {
+	keys   []string
+	values []reflect.Value
+}
+
+func (m mapEntries) Len() int           { return len(m.keys) }
+func (m mapEntries) Less(i, j int) bool { return m.keys[i] < m.keys[j] }
+func (m mapEntries) Swap(i, j int) {
+	m.keys[i], m.keys[j] = m.keys[j], m.keys[i]
+	m.values[i], m.values[j] = m.values[j], m.values[i]
+}
+
+func marshallStruct(enc *encoder, v reflect.Value) error {
+	enc.open('{')
+	n := 0
+	for _, f := range cachedTypeFields(v.Type()) {
+		fieldValue, ok := fieldByIndexIfSet(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fieldValue)) {
+			continue
+		}
+		enc.key(n, f.name)
+		n++
+		var err error
+		if f.quoted {
+			err = marshallQuoted(enc, fieldValue)
+		} else {
+			err = marshallValue(enc, fieldValue)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	enc.close('}', n)
+	return nil
+}
+
+// marshallQuoted handles the `string` tag option where a scalar is written inside a json string e.g. "123"
+func marshallQuoted(enc *encoder, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			enc.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+	quoted := &encoder{options: enc.options}
+	err := marshallValue(quoted, v)
+	if err != nil {
+		return err
+	}
+	marshallString(enc, quoted.String())
+	return nil
+}
+
+// fieldByIndexIfSet is reflect.Value.FieldByIndex but it reports false instead of panicking on nil embedded pointers
+func fieldByIndexIfSet(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue decides which fields are left out by the omitempty tag option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package json
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshall(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"Null", nil, `null`},
+		{"True", true, `true`},
+		{"False", false, `false`},
+		{"Int", int64(-123), `-123`},
+		{"Float", 0.25, `0.25`},
+		{"Float without a fraction", 3.0, `3`},
+		{"Float with exponent", 1e21, `1e+21`},
+		{"Simple String", "Key", `"Key"`},
+		{"String with escapes", "she said \"a\\b\"\n\t", `"she said \"a\\b\"\n\t"`},
+		{"String with control character", "a\x01b", `"a\u0001b"`},
+		{"String with unicode", "ሴé", `"ሴé"`},
+		{"String with invalid utf8", "a\xffb", `"a�b"`},
+		{"Empty Array", []any{}, `[]`},
+		{"Nested Array", []any{"v1", []any{int64(1), nil}}, `["v1",[1,null]]`},
+		{"Empty Object", map[string]any{}, `{}`},
+		{"Object", map[string]any{"k1": []any{true}}, `{"k1":[true]}`},
+		{"Nil Array", []any(nil), `null`},
+		{"Nil Object", map[string]any(nil), `null`},
+		{"Nil typed Array and Object", []any{[]int(nil), map[string]int(nil)}, `[null,null]`},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				output, err := Marshall(testcase.input)
+				assert.Nil(err)
+				assert.Equal(testcase.expected, string(output))
+			},
+		)
+	}
+}
+
+func TestMarshallUnsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Marshall(math.NaN())
+	assert.IsType(&UnsupportedValueError{}, err)
+	_, err = Marshall([]any{math.Inf(1)})
+	assert.IsType(&UnsupportedValueError{}, err)
+	_, err = Marshall(map[string]any{"k1": make(chan int)})
+	assert.IsType(&UnsupportedTypeError{}, err)
+}
+
+func TestMarshallOptions(t *testing.T) {
+	assert := assert.New(t)
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count,omitempty"`
+	}
+	ordered := NewOrderedObject()
+	ordered.Set("b", int64(1))
+	ordered.Set("a", int64(2))
+	testCases := []struct {
+		name     string
+		options  MarshallOptions
+		input    any
+		expected string
+	}{
+		{"sorted keys", MarshallOptions{SortKeys: true}, map[string]any{"b": int64(1), "a": map[string]any{"d": true, "c": nil}}, `{"a":{"c":null,"d":true},"b":1}`},
+		{"sorted integer keys", MarshallOptions{SortKeys: true}, map[int]string{10: "x", 2: "y", 1: "z"}, `{"1":"z","10":"x","2":"y"}`},
+		{"ordered objects keep their order", MarshallOptions{SortKeys: true}, ordered, `{"b":1,"a":2}`},
+		{"indent", MarshallOptions{Indent: "  "}, []any{int64(1), map[string]any{"a": []any{}}, map[string]any{}}, "[\n  1,\n  {\n    \"a\": []\n  },\n  {}\n]"},
+		{"prefix and indent", MarshallOptions{Prefix: "// ", Indent: "\t"}, map[string]any{"a": []any{true}}, "{\n// \t\"a\": [\n// \t\ttrue\n// \t]\n// }"},
+		{"indent a struct", MarshallOptions{Indent: " "}, []item{{Name: "x"}, {Name: "y", Count: 2}}, "[\n {\n  \"name\": \"x\"\n },\n {\n  \"name\": \"y\",\n  \"count\": 2\n }\n]"},
+		{"indent a scalar", MarshallOptions{Indent: " "}, "a", `"a"`},
+		{"no HTML escaping", MarshallOptions{}, "<a href='x'>&</a>", `"<a href='x'>&</a>"`},
+		{"HTML escaping", MarshallOptions{EscapeHTML: true}, "<a href='x'>&</a>", `"\u003ca href='x'\u003e\u0026\u003c/a\u003e"`},
+		{"HTML escaping of keys", MarshallOptions{EscapeHTML: true}, map[string]any{"<": ">"}, `{"\u003c":"\u003e"}`},
+		{"no ASCII escaping", MarshallOptions{}, "é ሴ 😀", `"é ሴ 😀"`},
+		{"ASCII escaping", MarshallOptions{EscapeNonASCII: true}, "é ሴ 😀\x01", `"\u00e9 \u1234 \ud83d\ude00\u0001"`},
+		{"ASCII escaping of invalid UTF-8", MarshallOptions{EscapeNonASCII: true}, "a\xffb", `"a\ufffdb"`},
+		{"no line separator escaping", MarshallOptions{}, "a\u2028b\u2029", "\"a\u2028b\u2029\""},
+		{"line separator escaping", MarshallOptions{EscapeLineSeparators: true}, "a\u2028b\u2029é", `"a\u2028b\u2029é"`},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				output, err := testcase.options.Marshall(testcase.input)
+				assert.Nil(err)
+				assert.Equal(testcase.expected, string(output))
+			},
+		)
+	}
+
+	// whatever the options, it reads back as the same thing
+	value := map[string]any{"text": "<é\u2028😀>", "list": []any{int64(1), 2.5, map[string]any{}}}
+	options := MarshallOptions{Prefix: "  ", Indent: "\t", SortKeys: true, EscapeHTML: true, EscapeNonASCII: true, EscapeLineSeparators: true}
+	output, err := options.Marshall(value)
+	assert.Nil(err)
+	assert.Equal(value, Unmarshall(output))
+	for _, char := range output {
+		assert.True(char < 0x80)
+	}
+}
+
+func TestMarshallFloat(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		input any
+		// what JavaScript's Number.prototype.toString gives
+		expected   string
+		withSuffix string
+	}{
+		{0.1, "0.1", "0.1"},
+		{0.30000000000000004, "0.30000000000000004", "0.30000000000000004"},
+		{-1.5, "-1.5", "-1.5"},
+		{100.0, "100", "100.0"},
+		{9007199254740992.0, "9007199254740992", "9007199254740992.0"},
+		{1e20, "100000000000000000000", "100000000000000000000.0"},
+		{1.2345678901234568e20, "123456789012345680000", "123456789012345680000.0"},
+		{1e21, "1e+21", "1e+21"},
+		{1.5e300, "1.5e+300", "1.5e+300"},
+		{math.MaxFloat64, "1.7976931348623157e+308", "1.7976931348623157e+308"},
+		{0.000001, "0.000001", "0.000001"},
+		{0.0000012345, "0.0000012345", "0.0000012345"},
+		{1e-7, "1e-7", "1e-7"},
+		{-1.23e-18, "-1.23e-18", "-1.23e-18"},
+		{5e-324, "5e-324", "5e-324"},
+		{0.0, "0", "0.0"},
+		{math.Copysign(0, -1), "0", "-0.0"},
+		{float32(0.1), "0.1", "0.1"},
+		{float32(16777216), "16777216", "16777216.0"},
+	}
+	for _, testcase := range testCases {
+		output, err := Marshall(testcase.input)
+		assert.Nil(err)
+		assert.Equal(testcase.expected, string(output))
+
+		output, err = MarshallOptions{FloatSuffix: true}.Marshall(testcase.input)
+		assert.Nil(err)
+		assert.Equal(testcase.withSuffix, string(output))
+		if _, ok := testcase.input.(float64); ok {
+			assert.Equal(testcase.input, Unmarshall(output))
+		}
+	}
+}
+
+func TestMarshallCycle(t *testing.T) {
+	assert := assert.New(t)
+	type node struct {
+		Name string
+		Next *node
+	}
+	loop := &node{Name: "a"}
+	loop.Next = &node{Name: "b", Next: loop}
+	array := []any{int64(1), nil}
+	array[1] = array
+	object := map[string]any{}
+	object["a"] = []any{object}
+	ordered := NewOrderedObject()
+	ordered.Set("a", ordered)
+	type tree map[string]tree
+	branches := tree{}
+	branches["b"] = tree{"c": branches}
+
+	for _, value := range []any{loop, array, object, ordered, branches} {
+		_, err := Marshall(value)
+		if assert.IsType(&UnsupportedValueError{}, err) {
+			assert.Contains(err.Error(), "contains itself")
+		}
+	}
+
+	// deep values that aren't cycles are fine
+	list := &node{}
+	for i := 0; i < 3*startDetectingCyclesAfter; i++ {
+		list = &node{Next: list}
+	}
+	output, err := Marshall(list)
+	assert.Nil(err)
+	assert.Equal(3*startDetectingCyclesAfter+1, bytes.Count(output, []byte("Next")))
+	nested := []any{}
+	for i := 0; i < 3*startDetectingCyclesAfter; i++ {
+		nested = []any{map[string]any{"a": nested}}
+	}
+	_, err = Marshall(nested)
+	assert.Nil(err)
+}
+
+func TestMarshallRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	for _, filename := range []string{"testdata/code.json", "testdata/map_of_string.json", "testdata/array_of_int.json"} {
+		str, err := ioutil.ReadFile(filename)
+		if err != nil {
+			panic(err)
+		}
+		expected := Unmarshall(str)
+		output, err := Marshall(expected)
+		assert.Nil(err)
+		assert.Equal(expected, Unmarshall(output), filename)
+	}
+	// by default everything comes back the same except whole floats, which are written like ints
+	value := []any{0.1, 3.0, math.Copysign(0, -1), 1e21, int64(3), "s", map[string]any{"f": 2.0}}
+	output, err := Marshall(value)
+	assert.Nil(err)
+	assert.Equal(`[0.1,3,0,1e+21,3,"s",{"f":2}]`, string(output))
+	assert.Equal([]any{0.1, int64(3), int64(0), 1e21, int64(3), "s", map[string]any{"f": int64(2)}}, Unmarshall(output))
+
+	// FloatSuffix keeps whole floats floats
+	expected := map[string]any{"k1": []any{0.1, math.Copysign(0, -1), 5.0, 1e-7, int64(math.MaxInt64), " \x00\"", map[string]any{}}}
+	output, err = MarshallOptions{FloatSuffix: true}.Marshall(expected)
+	assert.Nil(err)
+	assert.Equal(expected, Unmarshall(output))
+}
+
+func TestMarshallStruct(t *testing.T) {
+	assert := assert.New(t)
+	type Inner struct {
+		Note string `json:"note,omitempty"`
+	}
+	type Outer struct {
+		*Inner
+		Name    string            `json:"name"`
+		Count   int               `json:"count,string"`
+		Ratio   *float32          `json:"ratio"`
+		Skipped bool              `json:"skipped,omitempty"`
+		Ignored string            `json:"-"`
+		Scores  map[uint16]int8   `json:"scores"`
+		Labels  map[string]string `json:"labels,omitempty"`
+		Points  [2]int
+		List    []string
+		private int
+	}
+	ratio := float32(0.1)
+	output, err := Marshall(Outer{
+		Inner:   &Inner{Note: "hi"},
+		Name:    "ope",
+		Count:   12,
+		Ratio:   &ratio,
+		Ignored: "v",
+		Scores:  map[uint16]int8{7: -1},
+		Points:  [2]int{1, 2},
+		private: 1,
+	})
+	assert.Nil(err)
+	assert.Equal(`{"note":"hi","name":"ope","count":"12","ratio":0.1,"scores":{"7":-1},"Points":[1,2],"List":null}`, string(output))
+
+	// the nil embedded pointer is skipped
+	output, err = Marshall(&Outer{})
+	assert.Nil(err)
+	assert.Equal(`{"name":"","count":"0","ratio":null,"scores":null,"Points":[0,0],"List":null}`, string(output))
+
+	_, err = Marshall(map[bool]int{true: 1})
+	assert.IsType(&UnsupportedTypeError{}, err)
+	_, err = Marshall(struct{ F func() }{})
+	assert.IsType(&UnsupportedTypeError{}, err)
+}
+
+func TestMarshallStructRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	customer := "ope"
+	expected := Order{
+		Base:     Base{ID: 7, Created: "today"},
+		Extra:    &Extra{Note: "leave at the door"},
+		Items:    []Item{{Name: "apple", Quantity: 3, Price: 0.5}, {Name: "pear"}},
+		Tags:     [2]string{"a", "b"},
+		Counts:   map[string]int{"x": 1},
+		ByID:     map[int]*Item{12: {Name: "fig"}},
+		Customer: &customer,
+		Paid:     true,
+		Meta:     map[string]any{"k": []any{1.5}},
+		Nested:   map[string][]bool{"k": {true, false}},
+	}
+	output, err := Marshall(expected)
+	assert.Nil(err)
+	var order Order
+	assert.Nil(UnmarshallInto(output, &order))
+	assert.Equal(expected, order)
+}
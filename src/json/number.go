@@ -0,0 +1,55 @@
+package json
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Number is a json number kept as the literal it was in the json, e.g. "-1.5e3", so nothing is lost
+// until it is converted. Decoding with DecodeOptions{Numbers: UseNumberType} produces it and
+// Marshall writes it back as it is.
+type Number string
+
+// String returns the literal
+func (n Number) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as an int64. It fails if it has a fraction or exponent or doesn't fit.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Float64 returns the number as the closest float64. It fails if it is too big for a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// BigInt returns the number as a *big.Int, however big it is. It fails if it has a fraction or exponent.
+func (n Number) BigInt() (*big.Int, error) {
+	i, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not an integer", string(n))
+	}
+	return i, nil
+}
+
+// isNumberLiteral reports if s is a json number the way RFC 8259 writes them
+func isNumberLiteral(s string) bool {
+	iter := &iterator{s: []byte(s)}
+	return s != "" && scanNumber(iter, &DecodeOptions{}) == nil && !iter.HasNext()
+}
+
+// parseBigNumber is parseNumber for numbers that don't fit in an int64 or float64
+func parseBigNumber(literal []byte, isFloat bool) (any, error) {
+	if !isFloat {
+		return Number(literal).BigInt()
+	}
+	// enough precision for every digit of the literal
+	f, _, err := big.ParseFloat(string(literal), 10, uint(len(literal))*4, big.ToNearestEven)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
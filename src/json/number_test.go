@@ -0,0 +1,120 @@
+package json
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	assert := assert.New(t)
+
+	i, err := Number("-42").Int64()
+	assert.Nil(err)
+	assert.Equal(int64(-42), i)
+	_, err = Number("1.5").Int64()
+	assert.Error(err)
+	_, err = Number("9223372036854775808").Int64()
+	assert.Error(err)
+
+	f, err := Number("1.5e3").Float64()
+	assert.Nil(err)
+	assert.Equal(1500.0, f)
+
+	b, err := Number("123456789012345678901234567890").BigInt()
+	assert.Nil(err)
+	assert.Equal("123456789012345678901234567890", b.String())
+	_, err = Number("1e3").BigInt()
+	assert.Error(err)
+}
+
+func TestNumberPolicies(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`[1, 1.5, 18446744073709551615, 1e400]`)
+	bigInt, _ := new(big.Int).SetString("18446744073709551615", 10)
+
+	_, err := Decode(input)
+	assert.Error(err)
+
+	value, err := DecodeOptions{Numbers: UseNumberType}.Decode(input)
+	assert.Nil(err)
+	assert.Equal([]any{Number("1"), Number("1.5"), Number("18446744073709551615"), Number("1e400")}, value)
+
+	value, err = DecodeOptions{Numbers: BigOnOverflow}.Decode(input)
+	if assert.Nil(err) {
+		values := value.([]any)
+		assert.Equal(int64(1), values[0])
+		assert.Equal(1.5, values[1])
+		assert.Equal(bigInt, values[2])
+		if f, ok := values[3].(*big.Float); assert.True(ok) {
+			assert.Equal("1e+400", f.Text('g', -1))
+		}
+	}
+
+	value, err = DecodeOptions{Numbers: Float64Only}.Decode([]byte(`[1, 1.5, 18446744073709551615]`))
+	assert.Nil(err)
+	assert.Equal([]any{1.0, 1.5, 18446744073709551615.0}, value)
+
+	var values []any
+	handler := &valueCollector{values: &values}
+	assert.Nil(DecodeOptions{Numbers: UseNumberType}.Walk([]byte(`[1, 2.5]`), handler))
+	assert.Equal([]any{Number("1"), Number("2.5")}, values)
+}
+
+type valueCollector struct {
+	NoopHandler
+	values *[]any
+}
+
+func (c *valueCollector) OnValue(value any) error {
+	*c.values = append(*c.values, value)
+	return nil
+}
+
+func TestMarshallNumbers(t *testing.T) {
+	assert := assert.New(t)
+	bigInt, _ := new(big.Int).SetString("-18446744073709551615", 10)
+	output, err := Marshall([]any{Number("1.50"), Number(""), bigInt, big.NewFloat(5), (*big.Int)(nil)})
+	assert.Nil(err)
+	assert.Equal(`[1.50,0,-18446744073709551615,5,null]`, string(output))
+
+	_, err = Marshall(Number("1.5x"))
+	assert.Error(err)
+
+	output, err = Marshall(struct {
+		ID    Number
+		Big   *big.Int
+		Float *big.Float
+	}{ID: "12345678901234567890", Big: bigInt, Float: big.NewFloat(0.25)})
+	assert.Nil(err)
+	assert.Equal(`{"ID":12345678901234567890,"Big":-18446744073709551615,"Float":0.25}`, string(output))
+}
+
+func TestUnmarshallIntoWithNumberPolicies(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`{"ID": 18446744073709551615, "Count": 3, "Ratio": 0.5, "Raw": 1.10, "Big": 123456789012345678901234567890}`)
+	var target struct {
+		ID    uint64
+		Count int
+		Ratio float32
+		Raw   Number
+		Big   *big.Int
+	}
+	for _, policy := range []NumberPolicy{UseNumberType, BigOnOverflow} {
+		target.Raw = ""
+		target.Big = nil
+		assert.Nil(DecodeOptions{Numbers: policy}.UnmarshallInto(input, &target))
+		assert.Equal(uint64(18446744073709551615), target.ID)
+		assert.Equal(3, target.Count)
+		assert.Equal(float32(0.5), target.Ratio)
+		assert.Equal("123456789012345678901234567890", target.Big.String())
+	}
+	// UseNumberType keeps the literal exactly
+	assert.Nil(DecodeOptions{Numbers: UseNumberType}.UnmarshallInto(input, &target))
+	assert.Equal(Number("1.10"), target.Raw)
+
+	var small struct{ Count int8 }
+	err := DecodeOptions{Numbers: UseNumberType}.UnmarshallInto([]byte(`{"Count": 300}`), &small)
+	assert.IsType(&UnmarshallTypeError{}, err)
+}
@@ -0,0 +1,150 @@
+package json
+
+// DecodeOptions changes how json is read. The package level functions like Validate and Decode use
+// the zero value, call the methods of the same name on a DecodeOptions to read json with other options.
+type DecodeOptions struct {
+	// Lenient accepts some things the RFC 8259 grammar doesn't, the way this package always has:
+	// numbers with a leading + or leading zeros e.g. +1 and 0123 and numbers or literals that run
+	// into what comes after them without a delimiter e.g. truex
+	Lenient bool
+	// LoneSurrogates decides what happens to a \uXXXX escape for half of a UTF-16 surrogate pair
+	// that doesn't have the other half next to it.
+	LoneSurrogates LoneSurrogatePolicy
+	// InvalidUTF8 decides what happens to bytes in a string that are not valid UTF-8
+	InvalidUTF8 InvalidUTF8Policy
+	// MaxDepth is how deeply arrays and objects can be nested. 0 means DefaultMaxDepth and a
+	// negative number means there is no limit; the parser doesn't recurse so any depth is safe.
+	MaxDepth int
+	// DuplicateKeys decides what happens when a key appears more than once in an object.
+	// A Tokenizer or Walk reports every key, so for them only RejectDuplicateKeys makes a difference.
+	DuplicateKeys DuplicateKeyPolicy
+	// OrderedObjects makes Decode, Unmarshall and Decoder return objects as *OrderedObject, which keeps
+	// the keys in the order they are in the json, instead of map[string]any
+	OrderedObjects bool
+	// Numbers decides what Go type numbers are decoded as
+	Numbers NumberPolicy
+
+	// These limits stop untrusted input from using too much memory. 0 means there is no limit.
+	// Going over one is a *LimitError with the position where it happened.
+
+	// MaxInputSize is the most bytes the input can have. A Decoder won't read more than this from its stream.
+	MaxInputSize int
+	// MaxStringLength is the most bytes a string or key can have between its quotes
+	MaxStringLength int
+	// MaxNumberLength is the most characters a number literal can have
+	MaxNumberLength int
+	// MaxArrayLength is the most values an array can have
+	MaxArrayLength int
+	// MaxObjectKeys is the most keys an object can have, counting repeated keys each time
+	MaxObjectKeys int
+	// MaxNodes is the most values a document can have in total, counting arrays and objects and everything in them
+	MaxNodes int
+
+	// MaxErrors is the most problems ValidateAll reports. 0 means DefaultMaxErrors
+	MaxErrors int
+}
+
+// builder is the Builder for Decode, Unmarshall and Decoder
+func (opts *DecodeOptions) builder() Builder {
+	if opts.OrderedObjects {
+		return OrderedBuilder{DefaultBuilder{Numbers: opts.Numbers}}
+	}
+	return DefaultBuilder{Numbers: opts.Numbers}
+}
+
+// DefaultMaxDepth is the MaxDepth used when DecodeOptions doesn't set one
+const DefaultMaxDepth = 10000
+
+func (opts *DecodeOptions) maxDepth() int {
+	if opts.MaxDepth == 0 {
+		return DefaultMaxDepth
+	}
+	return opts.MaxDepth
+}
+
+// MarshallOptions changes how json is written. Marshall uses the zero value, which writes compact json
+// with only the escapes json needs.
+type MarshallOptions struct {
+	// Prefix and Indent put each value of an array or object on a line of its own that starts with Prefix
+	// followed by Indent once for each level of nesting. The json is compact if both are empty.
+	Prefix string
+	Indent string
+	// SortKeys writes the keys of maps in order. Struct fields and the keys of an *OrderedObject are
+	// always written in their own order.
+	SortKeys bool
+	// EscapeHTML writes <, > and & as \u003c, \u003e and \u0026 so the json can be put in a <script> tag
+	EscapeHTML bool
+	// EscapeNonASCII writes every character that is not ASCII as a \uXXXX escape, or a surrogate pair of
+	// them, so the json is plain ASCII
+	EscapeNonASCII bool
+	// EscapeLineSeparators writes U+2028 and U+2029 as \u2028 and \u2029. JavaScript before ES2019
+	// doesn't allow them in strings.
+	EscapeLineSeparators bool
+	// FloatSuffix ends floats that are whole numbers in .0, e.g. 3.0 rather than 3, and keeps the sign of -0.0
+	// so that Unmarshall reads them back as float64 and not int64. Otherwise floats are written exactly like
+	// JavaScript's Number.prototype.toString.
+	FloatSuffix bool
+	// Newline makes an Encoder write a new line after each value so it writes NDJSON, one value per line.
+	// Marshall ignores it.
+	Newline bool
+}
+
+// LoneSurrogatePolicy is what to do with half of a surrogate pair, e.g. "\ud83d" on its own.
+// The json grammar allows them but they are not valid unicode.
+type LoneSurrogatePolicy int
+
+const (
+	// ReplaceLoneSurrogates decodes them as U+FFFD, the replacement character. This is the default
+	ReplaceLoneSurrogates LoneSurrogatePolicy = iota
+	// RejectLoneSurrogates makes them a ValidationError
+	RejectLoneSurrogates
+	// KeepLoneSurrogates decodes them as the 3 bytes UTF-8 would use if it allowed surrogates (WTF-8)
+	// so that nothing is lost. The resulting string is not valid UTF-8.
+	KeepLoneSurrogates
+)
+
+// InvalidUTF8Policy is what to do with bytes in a string that are not valid UTF-8, e.g. "\xff".
+// RFC 8259 says json has to be UTF-8 but input from other systems isn't always.
+type InvalidUTF8Policy int
+
+const (
+	// RejectInvalidUTF8 makes them a ValidationError at the offset of the first bad byte. This is the default
+	RejectInvalidUTF8 InvalidUTF8Policy = iota
+	// ReplaceInvalidUTF8 decodes each bad byte as U+FFFD, the replacement character
+	ReplaceInvalidUTF8
+	// KeepInvalidUTF8 leaves them in the decoded string unchanged
+	KeepInvalidUTF8
+)
+
+// DuplicateKeyPolicy is what to do with a key that is repeated in an object, e.g. {"a": 1, "a": 2}.
+// RFC 8259 says the names in an object should be unique but doesn't say what to do if they aren't.
+type DuplicateKeyPolicy int
+
+const (
+	// LastKeyWins keeps the value of the last one. This is the default
+	LastKeyWins DuplicateKeyPolicy = iota
+	// FirstKeyWins keeps the value of the first one
+	FirstKeyWins
+	// RejectDuplicateKeys makes them a ValidationError that has the offsets of both keys
+	RejectDuplicateKeys
+	// CollectDuplicateKeys keeps every value, in order, in an array under the key e.g. {"a": [1, 2]}.
+	// Keys that only appear once keep their value as it is.
+	CollectDuplicateKeys
+)
+
+// NumberPolicy is what Go type json numbers are decoded as
+type NumberPolicy int
+
+const (
+	// Int64OrFloat64 makes integers int64 and numbers with a fraction or exponent float64.
+	// Numbers that don't fit are an error. This is the default
+	Int64OrFloat64 NumberPolicy = iota
+	// UseNumberType makes every number a Number so it can be converted later without losing anything
+	UseNumberType
+	// BigOnOverflow is Int64OrFloat64 but the numbers that don't fit are *big.Int or *big.Float instead of an error
+	BigOnOverflow
+	// Float64Only makes every number a float64, like encoding/json does
+	Float64Only
+	// DecimalNumbers makes every number an exact Decimal
+	DecimalNumbers
+)
@@ -0,0 +1,80 @@
+package json
+
+// OrderedObject is a json object that remembers the order of its keys.
+// Decoding with DecodeOptions.OrderedObjects makes objects *OrderedObject instead of map[string]any
+// and Marshall writes the keys back in the same order.
+type OrderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedObject returns an empty OrderedObject
+func NewOrderedObject() *OrderedObject {
+	return &OrderedObject{values: make(map[string]any)}
+}
+
+// Keys returns the keys in order. The slice is a copy so it is safe to change the object while going through it.
+func (o *OrderedObject) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Len returns the number of keys
+func (o *OrderedObject) Len() int {
+	return len(o.keys)
+}
+
+// Get returns the value of key and whether it is in the object
+func (o *OrderedObject) Get(key string) (any, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Set sets the value of key. A new key goes at the end and an existing one keeps its place.
+func (o *OrderedObject) Set(key string, value any) {
+	if o.values == nil {
+		o.values = make(map[string]any)
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key from the object, if it is there
+func (o *OrderedObject) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Range calls f for each key and value in order. If f returns false, Range stops.
+func (o *OrderedObject) Range(f func(key string, value any) bool) {
+	for _, key := range o.keys {
+		if !f(key, o.values[key]) {
+			return
+		}
+	}
+}
+
+// OrderedBuilder is DefaultBuilder but it builds *OrderedObject for objects
+type OrderedBuilder struct {
+	DefaultBuilder
+}
+
+// Object returns an empty *OrderedObject
+func (OrderedBuilder) Object() any {
+	return NewOrderedObject()
+}
+
+// SetKey sets key in the *OrderedObject returned by Object
+func (OrderedBuilder) SetKey(object any, key string, value any) any {
+	object.(*OrderedObject).Set(key, value)
+	return object
+}
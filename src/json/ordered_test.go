@@ -0,0 +1,90 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedObject(t *testing.T) {
+	assert := assert.New(t)
+	object := NewOrderedObject()
+	object.Set("b", int64(1))
+	object.Set("a", int64(2))
+	object.Set("c", int64(3))
+	object.Set("b", int64(4))
+	assert.Equal([]string{"b", "a", "c"}, object.Keys())
+
+	value, ok := object.Get("b")
+	assert.True(ok)
+	assert.Equal(int64(4), value)
+	_, ok = object.Get("d")
+	assert.False(ok)
+
+	object.Delete("a")
+	object.Delete("d")
+	assert.Equal([]string{"b", "c"}, object.Keys())
+	assert.Equal(2, object.Len())
+
+	var keys []string
+	object.Range(func(key string, value any) bool {
+		keys = append(keys, key)
+		return false
+	})
+	assert.Equal([]string{"b"}, keys)
+
+	var empty OrderedObject
+	empty.Set("a", nil)
+	assert.Equal([]string{"a"}, empty.Keys())
+}
+
+func TestOrderedObjectsRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	input := `{"zebra":1,"apple":{"y":[true,{"b":null,"a":"x"}],"x":2.5},"mango":"m"}`
+	opts := DecodeOptions{OrderedObjects: true}
+
+	value, err := opts.Decode([]byte(input))
+	if !assert.Nil(err) {
+		return
+	}
+	object, ok := value.(*OrderedObject)
+	if !assert.True(ok) {
+		return
+	}
+	assert.Equal([]string{"zebra", "apple", "mango"}, object.Keys())
+	output, err := Marshall(value)
+	assert.Nil(err)
+	assert.Equal(input, string(output))
+
+	assert.Equal(value, opts.Unmarshall([]byte(input)))
+	decoded, err := opts.NewDecoder(strings.NewReader(input)).Decode()
+	assert.Nil(err)
+	assert.Equal(value, decoded)
+
+	// it can be used in other values too
+	output, err = Marshall(struct {
+		Object *OrderedObject
+		Nil    *OrderedObject
+	}{Object: object})
+	assert.Nil(err)
+	assert.Equal(`{"Object":`+input+`,"Nil":null}`, string(output))
+
+	var target struct {
+		Zebra int
+		Apple map[string]any
+	}
+	assert.Nil(opts.UnmarshallInto([]byte(input), &target))
+	assert.Equal(1, target.Zebra)
+	assert.Equal(2.5, target.Apple["x"])
+
+	// an OrderedObject keeps the order without the option too
+	ordered := NewOrderedObject()
+	assert.Nil(UnmarshallInto([]byte(input), &ordered))
+	assert.Equal(object, ordered)
+	var plain OrderedObject
+	assert.Nil(UnmarshallInto([]byte(input), &plain))
+	assert.Equal([]string{"zebra", "apple", "mango"}, plain.Keys())
+	_, ok = UnmarshallInto([]byte(`[1]`), &plain).(*UnmarshallTypeError)
+	assert.True(ok)
+}
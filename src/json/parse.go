@@ -0,0 +1,501 @@
+package json
+
+import (
+	"io"
+	"strconv"
+)
+
+// Builder creates the values the parser reads. The parser checks the json and a Builder decides what,
+// if anything, to make out of it. Unmarshall and Decode use DefaultBuilder and Validate uses a Builder
+// that makes nothing.
+//
+// A Builder for custom containers can embed DefaultBuilder and only replace Object and SetKey or Array and Append.
+type Builder interface {
+	// Object returns a new empty object and SetKey adds a key to an object returned by Object
+	Object() any
+	SetKey(object any, key string, value any) any
+	// Array returns a new empty array and Append adds a value to the end of an array returned by Array
+	Array() any
+	Append(array any, value any) any
+	String(s string) any
+	// Number is given the number literal as it appears in the json e.g. -1.5e3
+	Number(literal []byte) (any, error)
+	Bool(b bool) any
+	Null() any
+}
+
+// DefaultBuilder builds map[string]any for objects, []any for arrays, int64 or float64 for numbers
+// and string, bool or nil for the rest. Numbers can be changed to other types with a NumberPolicy.
+type DefaultBuilder struct {
+	Numbers NumberPolicy
+}
+
+// Object returns a map[string]any
+func (DefaultBuilder) Object() any {
+	return make(map[string]any)
+}
+
+// SetKey sets key in the map[string]any returned by Object
+func (DefaultBuilder) SetKey(object any, key string, value any) any {
+	object.(map[string]any)[key] = value
+	return object
+}
+
+// Array returns a []any
+func (DefaultBuilder) Array() any {
+	return make([]any, 0)
+}
+
+// Append appends to the []any returned by Array
+func (DefaultBuilder) Append(array any, value any) any {
+	return append(array.([]any), value)
+}
+
+// String returns s
+func (DefaultBuilder) String(s string) any {
+	return s
+}
+
+// Number returns an int64, or a float64 if the literal has a fraction or an exponent,
+// unless the NumberPolicy says otherwise
+func (b DefaultBuilder) Number(literal []byte) (any, error) {
+	isFloat := false
+	for _, char := range literal {
+		if char == '.' || char == 'e' || char == 'E' {
+			isFloat = true
+			break
+		}
+	}
+	switch b.Numbers {
+	case UseNumberType:
+		return Number(literal), nil
+	case Float64Only:
+		return parseNumber(literal, true)
+	case DecimalNumbers:
+		return ParseDecimal(strictNumber(literal))
+	case BigOnOverflow:
+		value, err := parseNumber(literal, isFloat)
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return parseBigNumber(literal, isFloat)
+		}
+		return value, err
+	default:
+		return parseNumber(literal, isFloat)
+	}
+}
+
+// strictNumber drops the + and the leading zeros that Lenient allows in literal, e.g. +0012.5 is 12.5
+func strictNumber(literal []byte) string {
+	sign := ""
+	switch {
+	case len(literal) > 0 && literal[0] == '+':
+		literal = literal[1:]
+	case len(literal) > 0 && literal[0] == '-':
+		sign, literal = "-", literal[1:]
+	}
+	for len(literal) > 1 && literal[0] == '0' && isDigit(literal[1]) {
+		literal = literal[1:]
+	}
+	return sign + string(literal)
+}
+
+// Bool returns b
+func (DefaultBuilder) Bool(b bool) any {
+	return b
+}
+
+// Null returns nil
+func (DefaultBuilder) Null() any {
+	return nil
+}
+
+// noopBuilder is the Builder for Validate. It makes nothing.
+type noopBuilder struct{}
+
+func (noopBuilder) Object() any                                  { return nil }
+func (noopBuilder) SetKey(object any, key string, value any) any { return nil }
+func (noopBuilder) Array() any                                   { return nil }
+func (noopBuilder) Append(array any, value any) any              { return nil }
+func (noopBuilder) String(s string) any                          { return nil }
+func (noopBuilder) Number(literal []byte) (any, error)           { return nil, nil }
+func (noopBuilder) Bool(b bool) any                              { return nil }
+func (noopBuilder) Null() any                                    { return nil }
+
+// DecodeWith is Decode but the values are made by builder
+func DecodeWith(data []byte, builder Builder) (any, error) {
+	return DecodeOptions{}.DecodeWith(data, builder)
+}
+
+// DecodeWith is DecodeWith using these options
+func (opts DecodeOptions) DecodeWith(data []byte, builder Builder) (any, error) {
+	p := newParser(&iterator{s: data}, builder, &opts)
+	value, err := p.parseDocument()
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	return value, nil
+}
+
+// parser builds values from the tokens of a Tokenizer. The Tokenizer is the one place that follows the json
+// grammar so Validate, Unmarshall, Decode, Walk and ValidateAll all find the same problems.
+type parser struct {
+	tokens  *Tokenizer
+	builder Builder
+	// plainArrays is true if the arrays are DefaultBuilder's []any, which the parser appends to itself
+	plainArrays bool
+}
+
+func newParser(iter *iterator, builder Builder, options *DecodeOptions) *parser {
+	p := &parser{tokens: &Tokenizer{iter: iter, options: options, stringStart: -1}, builder: builder}
+	switch builder.(type) {
+	case DefaultBuilder, OrderedBuilder:
+		p.plainArrays = true
+	}
+	return p
+}
+
+// parseDocument parses a value that has nothing but whitespace after it
+func (p *parser) parseDocument() (any, error) {
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	// this is where the Tokenizer finds anything after the value
+	_, err = p.tokens.Next()
+	if err != io.EOF {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parseValue reads a single value
+func (p *parser) parseValue() (any, error) {
+	value, err := p.parseNested()
+	if sizeErr := checkStreamSize(p.tokens.iter, p.tokens.options, err); sizeErr != nil {
+		return nil, sizeErr
+	}
+	return value, err
+}
+
+// built is an array or object parseNested is in the middle of building
+type built struct {
+	container any
+	items     []any
+}
+
+// parseNested does the work of parseValue. It doesn't recurse for nested arrays and objects, the ones it is
+// in the middle of are kept in containers, and where it is in them in the frames of the Tokenizer, so that
+// deeply nested json can't run out of stack.
+func (p *parser) parseNested() (any, error) {
+	t := p.tokens
+	// container is the innermost array or object built so far and containers are the ones it is in.
+	// With plainArrays, an array is kept in items instead, so the slice isn't put in an any for every Append
+	var container any
+	var items []any
+	var containers []built
+	var token Token
+	for {
+		err := t.read(&token)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		switch token.Kind {
+		case ObjectStart:
+			containers = append(containers, built{container, items})
+			container = p.builder.Object()
+			continue
+		case ArrayStart:
+			containers = append(containers, built{container, items})
+			if p.plainArrays {
+				items = make([]any, 0)
+			} else {
+				container = p.builder.Array()
+			}
+			continue
+		case Key:
+			// the Tokenizer keeps the key in its frame for setMember
+			continue
+		case ObjectEnd, ArrayEnd:
+			value = container
+			if token.Kind == ArrayEnd && p.plainArrays {
+				value = items
+			}
+			container, items = containers[len(containers)-1].container, containers[len(containers)-1].items
+			containers = containers[:len(containers)-1]
+		case String:
+			value = p.builder.String(token.Text)
+		case NumberLiteral:
+			value, err = p.builder.Number(token.Raw)
+			if err != nil {
+				return nil, withFramePath(numberError(t.iter, token.Offset, err), t.stack, true)
+			}
+		case Bool:
+			value = p.builder.Bool(token.Bool)
+		case Null:
+			value = p.builder.Null()
+		}
+
+		// value is finished so add it to the container it is in, unless it is the whole thing
+		if len(t.stack) == 0 {
+			return value, nil
+		}
+		top := &t.stack[len(t.stack)-1]
+		switch {
+		case top.isObject:
+			container = p.setMember(top, container, value)
+		case p.plainArrays:
+			items = append(items, value)
+		default:
+			container = p.builder.Append(container, value)
+		}
+	}
+}
+
+// setMember adds the value of the current key to object, according to the DuplicateKeys policy
+func (p *parser) setMember(top *frame, object any, value any) any {
+	if !top.duplicate {
+		if p.tokens.options.DuplicateKeys == CollectDuplicateKeys {
+			top.seen[top.key].value = value
+		}
+		return p.builder.SetKey(object, top.key, value)
+	}
+	switch p.tokens.options.DuplicateKeys {
+	case FirstKeyWins:
+		return object
+	case CollectDuplicateKeys:
+		seen := top.seen[top.key]
+		if seen.collected == nil {
+			seen.collected = p.builder.Append(p.builder.Array(), seen.value)
+		}
+		seen.collected = p.builder.Append(seen.collected, value)
+		return p.builder.SetKey(object, top.key, seen.collected)
+	default:
+		return p.builder.SetKey(object, top.key, value)
+	}
+}
+
+// numberError is the error for a number at offset that a Builder couldn't make a value of, e.g. one too big for an int64
+func numberError(iter *iterator, offset int, err error) error {
+	return withRule(newValidationError(iter, ErrInvalidNumber, offset, "a number", "This error %s occurred while trying to parse a number", err), ruleNumber)
+}
+
+// a frame is an object or array the parser or a Tokenizer is in the middle of
+type frame struct {
+	isObject bool
+	index    int
+	key      string
+	// seen has the keys of the object so far, it is only kept if there is a DuplicateKeys policy to follow.
+	// duplicate is true if key is one of them
+	seen      map[string]*seenKey
+	duplicate bool
+}
+
+type seenKey struct {
+	offset int
+	// value is the first value of the key and collected all of them, for CollectDuplicateKeys
+	value     any
+	collected any
+}
+
+// setKey makes key the current key of the object, checking if it is a duplicate
+func (f *frame) setKey(iter *iterator, key string, keyStart int, options *DecodeOptions) error {
+	f.key = key
+	if options.DuplicateKeys == LastKeyWins {
+		return nil
+	}
+	if f.seen == nil {
+		f.seen = make(map[string]*seenKey)
+	}
+	seen, ok := f.seen[key]
+	f.duplicate = ok
+	if !ok {
+		f.seen[key] = &seenKey{offset: keyStart}
+		return nil
+	}
+	if options.DuplicateKeys == RejectDuplicateKeys {
+		err := newValidationError(iter, ErrDuplicateKey, keyStart, "a key that is not already in the object", "The key %q is repeated, it was first used at offset %d", key, seen.offset)
+		err.FirstOffset = seen.offset
+		return withParent(err, key)
+	}
+	return nil
+}
+
+// withFramePath fills in the Path of a ValidationError from the frames, the way a recursive parser would
+// as it returned. Problems with a value include the key or index of that value, problems with the punctuation
+// of an object or array don't.
+func withFramePath(err error, frames []frame, inValue bool) error {
+	for i := len(frames) - 1; i >= 0; i-- {
+		if i == len(frames)-1 && !inValue {
+			continue
+		}
+		if frames[i].isObject {
+			err = withParent(err, frames[i].key)
+		} else {
+			err = withParent(err, strconv.Itoa(frames[i].index))
+		}
+	}
+	return err
+}
+
+func closing(isObject bool) byte {
+	if isObject {
+		return '}'
+	}
+	return ']'
+}
+
+func containerRule(isObject bool) string {
+	if isObject {
+		return ruleObject
+	}
+	return ruleArray
+}
+
+// These are the rules of the RFC 8259 grammar that a ValidationError can name
+const (
+	ruleJSONText = "JSON-text = ws value ws"
+	ruleValue    = "value = false / null / true / object / array / number / string"
+	ruleObject   = "object = begin-object [ member *( value-separator member ) ] end-object"
+	ruleMember   = "member = string name-separator value"
+	ruleArray    = "array = begin-array [ value *( value-separator value ) ] end-array"
+	ruleNumber   = "number = [ minus ] int [ frac ] [ exp ]"
+	ruleInt      = "int = zero / ( digit1-9 *DIGIT )"
+	ruleFrac     = "frac = decimal-point 1*DIGIT"
+	ruleExp      = "exp = e [ minus / plus ] 1*DIGIT"
+	ruleString   = "string = quotation-mark *char quotation-mark"
+	ruleChar     = "char = unescaped / escape ( %x22 / %x5C / %x2F / %x62 / %x66 / %x6E / %x72 / %x74 / %x75 4HEXDIG )"
+)
+
+var literalRules = map[string]string{
+	"false": "false = %x66.61.6c.73.65",
+	"null":  "null  = %x6e.75.6c.6c",
+	"true":  "true  = %x74.72.75.65",
+}
+
+// The scan functions read a single token and check it is valid
+
+func scanLiteral(iter *iterator, literal string, options *DecodeOptions) error {
+	for _, char := range literal {
+		if rune(iter.Current()) != char {
+			return withRule(newValidationError(iter, ErrInvalidLiteral, iter.Cursor(), literal, "Error when trying to unmarshall '%v'", literal), literalRules[literal])
+		}
+		iter.Next()
+	}
+	if !options.Lenient && !isDelimiter(iter) {
+		return withRule(newValidationError(iter, ErrInvalidLiteral, iter.Cursor(), "a delimiter", "%s needs to be followed by whitespace, ',', ']', '}' or the end", literal), ruleValue)
+	}
+	return nil
+}
+
+// isDelimiter reports if the cursor is on something that can come straight after a number or a literal
+func isDelimiter(iter *iterator) bool {
+	if !iter.HasNext() {
+		return true
+	}
+	switch iter.Current() {
+	case ',', ']', '}':
+		return true
+	}
+	return isSpace(iter.Current())
+}
+
+func isNumber(iter *iterator) bool {
+	switch iter.Current() {
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9', '-', '0', '+':
+		return true
+	}
+	return false
+}
+
+func isDigit(char byte) bool {
+	return '0' <= char && char <= '9'
+}
+
+func scanNumber(iter *iterator, options *DecodeOptions) error {
+	// maybe this should be an explicit state machine
+	start := iter.Cursor()
+	if iter.Current() == '+' && !options.Lenient {
+		return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "'-' or a digit", "Numbers can't start with +"), ruleNumber)
+	}
+	hasSign := false
+	if (iter.Current() == '-') || (iter.Current() == '+') {
+		iter.Next()
+		hasSign = true
+	}
+	// there needs to be a digit after - or +
+	if hasSign && !isDigit(iter.Current()) {
+		return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "a digit", "There needs to be a digit after - or +"), ruleInt)
+	}
+	if iter.Current() == '0' && !options.Lenient {
+		iter.Next()
+		if isDigit(iter.Current()) {
+			return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "'.', 'e', 'E' or the end of the number", "Numbers can't have leading zeros"), ruleInt)
+		}
+	}
+	err := scanDigits(iter, start, options)
+	if err != nil {
+		return err
+	}
+	if iter.Current() == '.' {
+		iter.Next()
+		if !isDigit(iter.Current()) {
+			return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "a digit", "There needs to be a digit after . "), ruleFrac)
+		}
+		err = scanDigits(iter, start, options)
+		if err != nil {
+			return err
+		}
+	}
+	if (iter.Current() == 'e') || (iter.Current() == 'E') {
+		iter.Next()
+		if (iter.Current() == '-') || (iter.Current() == '+') {
+			iter.Next()
+		}
+		// make sure there is at least one digit after e/E
+		if !isDigit(iter.Current()) {
+			return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "a digit", "There needs to be at least one digit after e/E when parsing a number"), ruleExp)
+		}
+		err = scanDigits(iter, start, options)
+		if err != nil {
+			return err
+		}
+	}
+	err = checkNumberLength(iter, start, options)
+	if err != nil {
+		return err
+	}
+	if !options.Lenient && !isDelimiter(iter) {
+		return withRule(newValidationError(iter, ErrInvalidNumber, iter.Cursor(), "a delimiter", "Numbers need to be followed by whitespace, ',', ']', '}' or the end"), ruleNumber)
+	}
+	return nil
+}
+
+// scanDigits reads a run of digits of the number that started at start. It stops as soon as the number
+// is longer than MaxNumberLength so a stream doesn't have to keep all of a huge one in memory.
+func scanDigits(iter *iterator, start int, options *DecodeOptions) error {
+	for isDigit(iter.Current()) {
+		iter.Next()
+		err := checkNumberLength(iter, start, options)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseNumber turns a number literal into an int64, or a float64 if isFloat
+func parseNumber(literal []byte, isFloat bool) (any, error) {
+	if isFloat {
+		floatValue, err := strconv.ParseFloat(string(literal), 64)
+		if err != nil {
+			return nil, err
+		}
+		return floatValue, nil
+	}
+	intValue, err := strconv.ParseInt(string(literal), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return intValue, nil
+}
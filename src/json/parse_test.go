@@ -0,0 +1,143 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAndDecodeAgree(t *testing.T) {
+	assert := assert.New(t)
+	inputs := []string{`1e`, `-`, `1-5`, `1.e5`, `[1,]`, `{"a" 1}`, `"\q"`, `[1 2]`, `1e5`, `-0.5E-3`, `[{"a": [null]}]`}
+	for _, input := range inputs {
+		t.Run(
+			input,
+			func(t *testing.T) {
+				_, err := Decode([]byte(input))
+				validationErr := Validate([]byte(input))
+				if validationErr == nil {
+					assert.Nil(err)
+					return
+				}
+				assert.Equal(&SyntaxError{validationErr.(ValidationError)}, err)
+			},
+		)
+	}
+}
+
+// upperBuilder makes every string upper case and objects into a list of keys
+type upperBuilder struct {
+	DefaultBuilder
+}
+
+func (upperBuilder) String(s string) any {
+	return strings.ToUpper(s)
+}
+
+func (upperBuilder) Object() any {
+	return []string{}
+}
+
+func (upperBuilder) SetKey(object any, key string, value any) any {
+	return append(object.([]string), key)
+}
+
+func TestDecodeWith(t *testing.T) {
+	assert := assert.New(t)
+	value, err := DecodeWith([]byte(`["a", {"k1": 1, "k2": 2}, 3]`), upperBuilder{})
+	assert.Nil(err)
+	assert.Equal([]any{"A", []string{"k1", "k2"}, int64(3)}, value)
+
+	_, err = DecodeWith([]byte(`["a", {"k1": 1, "k2": 2}, 3`), upperBuilder{})
+	assert.IsType(&SyntaxError{}, err)
+}
+
+func TestMaxDepth(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`{"a": [1, {"b": [[]]}]}`)
+
+	assert.Nil(DecodeOptions{MaxDepth: 5}.Validate(input))
+	err, ok := DecodeOptions{MaxDepth: 4}.Validate(input).(*LimitError)
+	if assert.True(ok) {
+		assert.Equal(DepthLimit, err.Limit)
+		assert.True(errors.Is(err, ErrDepthExceeded))
+		assert.True(errors.Is(err, ErrLimitExceeded))
+		assert.Equal(17, err.Offset)
+		assert.Equal("/a/1/b/0", err.Path)
+	}
+
+	tokenizer := DecodeOptions{MaxDepth: 4}.NewTokenizer(input)
+	var tokenizerErr error
+	for tokenizerErr == nil {
+		_, tokenizerErr = tokenizer.Next()
+	}
+	assert.Equal(err, tokenizerErr)
+
+	deep := []byte(strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth))
+	assert.Nil(Validate(deep))
+	deeper := []byte("[" + string(deep) + "]")
+	assert.Error(Validate(deeper))
+	assert.Panics(func() { Unmarshall(deeper) })
+}
+
+func TestNoMaxDepth(t *testing.T) {
+	assert := assert.New(t)
+	depth := 1000000
+	input := []byte(strings.Repeat("[", depth) + "1" + strings.Repeat("]", depth))
+	value, err := DecodeOptions{MaxDepth: -1}.Decode(input)
+	if !assert.Nil(err) {
+		return
+	}
+	for i := 0; i < depth; i++ {
+		value = value.([]any)[0]
+	}
+	assert.Equal(int64(1), value)
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`{"a": 1, "b": {"c": true}, "a": 2, "a": [3]}`)
+	testCases := []struct {
+		name     string
+		policy   DuplicateKeyPolicy
+		expected any
+	}{
+		{"last wins", LastKeyWins, map[string]any{"a": []any{int64(3)}, "b": map[string]any{"c": true}}},
+		{"first wins", FirstKeyWins, map[string]any{"a": int64(1), "b": map[string]any{"c": true}}},
+		{"collect", CollectDuplicateKeys, map[string]any{"a": []any{int64(1), int64(2), []any{int64(3)}}, "b": map[string]any{"c": true}}},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				value, err := DecodeOptions{DuplicateKeys: testcase.policy}.Decode(input)
+				assert.Nil(err)
+				assert.Equal(testcase.expected, value)
+			},
+		)
+	}
+
+	assert.Nil(Validate(input))
+	opts := DecodeOptions{DuplicateKeys: RejectDuplicateKeys}
+	err, ok := opts.Validate([]byte(`[{"a": {"b": 1, "b": 2}}]`)).(ValidationError)
+	if assert.True(ok) {
+		assert.True(errors.Is(err, ErrDuplicateKey))
+		assert.Equal(8, err.FirstOffset)
+		assert.Equal(16, err.Offset)
+		assert.Equal("/0/a/b", err.Path)
+	}
+	_, decodeErr := opts.Decode([]byte(`[{"a": {"b": 1, "b": 2}}]`))
+	assert.Equal(&SyntaxError{err}, decodeErr)
+
+	tokenizer := opts.NewTokenizer([]byte(`[{"a": {"b": 1, "b": 2}}]`))
+	var tokenizerErr error
+	for tokenizerErr == nil {
+		_, tokenizerErr = tokenizer.Next()
+	}
+	assert.Equal(err, tokenizerErr)
+
+	// the same key in different objects is fine
+	assert.Nil(opts.Validate([]byte(`[{"a": 1}, {"a": {"a": 2}}]`)))
+}
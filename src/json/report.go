@@ -0,0 +1,170 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultReportWidth is the widest a line of the input is shown by an ErrorReport with no Width
+const DefaultReportWidth = 100
+
+// ErrorReport shows where an error is in the input, for people rather than programs. It looks like this
+//
+//	Was expecting ',' but got '2' instead. There might be a comma missing
+//	 --> line 2, column 15, at /items
+//	  |
+//	2 |   "items": [1 2]
+//	  |               ^
+//
+// The zero value is ready to use.
+type ErrorReport struct {
+	// Context is the number of lines to show before and after the line with the problem
+	Context int
+	// Color adds ANSI escape codes to colour the report in a terminal
+	Color bool
+	// TabWidth is where the tab stops are, 0 means 4
+	TabWidth int
+	// Width is the most characters of a line to show, long lines are cut down to the part around the problem.
+	// 0 means DefaultReportWidth and a negative Width means lines are never cut.
+	Width int
+}
+
+// Report returns an ErrorReport of err with the default settings
+func Report(data []byte, err error) string {
+	return ErrorReport{}.Render(data, err)
+}
+
+// Render returns the report of err, which should have come from reading data.
+// Errors that don't say where the problem is, like the ones from an io.Reader, are just err.Error().
+// There is nothing to report for a nil err so that is "".
+func (r ErrorReport) Render(data []byte, err error) string {
+	var validationErr ValidationError
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case ValidationError:
+		validationErr = e
+	case *SyntaxError:
+		validationErr = e.ValidationError
+	case *LimitError:
+		validationErr = e.ValidationError
+	default:
+		return err.Error()
+	}
+	offset := validationErr.Offset
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// the lines to show, with the one the problem is on at index problem
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	lineNumber := bytes.Count(data[:lineStart], []byte{'\n'}) + 1
+	var lines [][]byte
+	start := lineStart
+	for i := 0; i < r.Context && start > 0; i++ {
+		start = bytes.LastIndexByte(data[:start-1], '\n') + 1
+	}
+	firstNumber := lineNumber - bytes.Count(data[start:lineStart], []byte{'\n'})
+	problem := lineNumber - firstNumber
+	for i := 0; i <= problem+r.Context; i++ {
+		end := bytes.IndexByte(data[start:], '\n')
+		if end < 0 {
+			lines = append(lines, data[start:])
+			break
+		}
+		lines = append(lines, data[start:start+end])
+		start += end + 1
+	}
+
+	tabWidth := r.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	cells := make([][]rune, len(lines))
+	for i, line := range lines {
+		cells[i] = expandLine(bytes.TrimSuffix(line, []byte{'\r'}), tabWidth)
+	}
+	caret := len(expandLine(data[lineStart:offset], tabWidth))
+	column := utf8.RuneCount(data[lineStart:offset]) + 1
+
+	// cut the lines down to the same window around the caret so that they still line up
+	width := r.Width
+	if width == 0 {
+		width = DefaultReportWidth
+	}
+	windowStart := 0
+	if width > 0 && len(cells[problem]) > width {
+		windowStart = caret - width/2
+		if windowStart > len(cells[problem])-width {
+			windowStart = len(cells[problem]) - width
+		}
+		if windowStart < 0 {
+			windowStart = 0
+		}
+	}
+
+	red, blue, bold, reset := "", "", "", ""
+	if r.Color {
+		red, blue, bold, reset = "\x1b[1;31m", "\x1b[34m", "\x1b[1m", "\x1b[0m"
+	}
+	gutter := len(strconv.Itoa(firstNumber + len(lines) - 1))
+	empty := strings.Repeat(" ", gutter)
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "%s%s%s\n", bold, validationErr.Error(), reset)
+	fmt.Fprintf(&report, "%s%s-->%s line %d, column %d", empty, blue, reset, lineNumber, column)
+	if validationErr.Path != "" {
+		fmt.Fprintf(&report, ", at %s", validationErr.Path)
+	}
+	fmt.Fprintf(&report, "\n%s%s |%s\n", empty, blue, reset)
+	for i, line := range cells {
+		shown := line
+		prefix := ""
+		if windowStart > 0 {
+			prefix = "…"
+			if windowStart < len(shown) {
+				shown = shown[windowStart:]
+			} else {
+				shown = nil
+			}
+		}
+		if width > 0 && len(shown) > width {
+			shown = append(shown[:width:width], '…')
+		}
+		number := strconv.Itoa(firstNumber + i)
+		row := fmt.Sprintf("%s%s%s |%s %s%s", blue, empty[len(number):], number, reset, prefix, string(shown))
+		report.WriteString(strings.TrimRight(row, " ") + "\n")
+		if i == problem {
+			pad := caret - windowStart + len([]rune(prefix))
+			fmt.Fprintf(&report, "%s%s |%s %s%s^%s\n", blue, empty, reset, strings.Repeat(" ", pad), red, reset)
+		}
+	}
+	return strings.TrimSuffix(report.String(), "\n")
+}
+
+// expandLine turns line into what a terminal shows: one rune per column, with tabs turned into spaces up to the
+// next tab stop. Other control characters and bytes that aren't UTF-8 become U+FFFD so they don't mess up the report.
+func expandLine(line []byte, tabWidth int) []rune {
+	cells := make([]rune, 0, len(line))
+	for len(line) > 0 {
+		char, size := utf8.DecodeRune(line)
+		line = line[size:]
+		switch {
+		case char == '\t':
+			for spaces := tabWidth - len(cells)%tabWidth; spaces > 0; spaces-- {
+				cells = append(cells, ' ')
+			}
+		case char < ' ' || char == 0x7f:
+			cells = append(cells, utf8.RuneError)
+		default:
+			cells = append(cells, char)
+		}
+	}
+	return cells
+}
@@ -0,0 +1,223 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorReport(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		name     string
+		report   ErrorReport
+		input    string
+		expected []string
+	}{
+		{
+			"one line",
+			ErrorReport{},
+			`[1, x]`,
+			[]string{
+				"Unknown value at 4",
+				" --> line 1, column 5, at /1",
+				"  |",
+				"1 | [1, x]",
+				"  |     ^",
+			},
+		},
+		{
+			"the line the problem is on",
+			ErrorReport{},
+			"{\n  \"items\": [1 2]\n}",
+			[]string{
+				"Was expecting ',' but got '2' instead. There might be a comma missing",
+				" --> line 2, column 15, at /items",
+				"  |",
+				"2 |   \"items\": [1 2]",
+				"  |               ^",
+			},
+		},
+		{
+			"a key that isn't a string",
+			ErrorReport{},
+			`{"a": 1, b: 2}`,
+			[]string{
+				"Was expecting a string key but got 'b' instead. Keys need to be in double quotes",
+				" --> line 1, column 10",
+				"  |",
+				"1 | {\"a\": 1, b: 2}",
+				"  |          ^",
+			},
+		},
+		{
+			"context",
+			ErrorReport{Context: 1},
+			"[\n\n1,\n2,\nx,\n3,\n]",
+			[]string{
+				"Unknown value at 9",
+				" --> line 5, column 1, at /2",
+				"  |",
+				"4 | 2,",
+				"5 | x,",
+				"  | ^",
+				"6 | 3,",
+			},
+		},
+		{
+			"context at the start and end",
+			ErrorReport{Context: 2},
+			"[\n1 2]",
+			[]string{
+				"Was expecting ',' but got '2' instead. There might be a comma missing",
+				" --> line 2, column 3",
+				"  |",
+				"1 | [",
+				"2 | 1 2]",
+				"  |   ^",
+			},
+		},
+		{
+			"wider gutter",
+			ErrorReport{Context: 1},
+			strings.Repeat("\n", 9) + "x",
+			[]string{
+				"Unknown value at 9",
+				"  --> line 10, column 1",
+				"   |",
+				" 9 |",
+				"10 | x",
+				"   | ^",
+			},
+		},
+		{
+			"runes",
+			ErrorReport{},
+			`["héllo", "wörld" x]`,
+			[]string{
+				"Was expecting ',' but got 'x' instead",
+				" --> line 1, column 19",
+				"  |",
+				`1 | ["héllo", "wörld" x]`,
+				"  |                   ^",
+			},
+		},
+		{
+			"tabs",
+			ErrorReport{TabWidth: 4},
+			"{\n\t\"a\":\t[1, x]}",
+			[]string{
+				"Unknown value at 12",
+				" --> line 2, column 11, at /a/1",
+				"  |",
+				`2 |     "a":    [1, x]}`,
+				"  |                 ^",
+			},
+		},
+		{
+			"windows newlines",
+			ErrorReport{},
+			"[\r\n1,\r\nx]",
+			[]string{
+				"Unknown value at 7",
+				" --> line 3, column 1, at /1",
+				"  |",
+				"3 | x]",
+				"  | ^",
+			},
+		},
+		{
+			"end of input",
+			ErrorReport{},
+			"[1\n",
+			[]string{
+				"Was expecting ',' but we are at the end",
+				" --> line 2, column 1",
+				"  |",
+				"2 |",
+				"  | ^",
+			},
+		},
+		{
+			"long line",
+			ErrorReport{Width: 10},
+			`[1, 2, 3, 4, 5, 6, 7, 8, x, 9, 10, 11, 12]`,
+			[]string{
+				"Unknown value at 25",
+				" --> line 1, column 26, at /8",
+				"  |",
+				"1 | …, 8, x, 9,…",
+				"  |       ^",
+			},
+		},
+		{
+			"long line with the problem at the end",
+			ErrorReport{Width: 10},
+			`[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, x]`,
+			[]string{
+				"Unknown value at 36",
+				" --> line 1, column 37, at /11",
+				"  |",
+				"1 | …10, 11, x]",
+				"  |          ^",
+			},
+		},
+		{
+			"long line is kept",
+			ErrorReport{Width: -1},
+			strings.Repeat(" ", 200) + "x",
+			[]string{
+				"Unknown value at 200",
+				" --> line 1, column 201",
+				"  |",
+				"1 | " + strings.Repeat(" ", 200) + "x",
+				"  | " + strings.Repeat(" ", 200) + "^",
+			},
+		},
+		{
+			"control characters",
+			ErrorReport{},
+			"[\"a\x01\"]",
+			[]string{
+				"Control characters need to be escaped in strings",
+				" --> line 1, column 4, at /0",
+				"  |",
+				"1 | [\"a�\"]",
+				"  |    ^",
+			},
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err := Validate([]byte(testcase.input))
+				assert.Equal(strings.Join(testcase.expected, "\n"), testcase.report.Render([]byte(testcase.input), err))
+			},
+		)
+	}
+
+	// the errors Decode and the limits return work too
+	input := []byte(`[1, x]`)
+	_, err := Decode(input)
+	assert.Equal(Report(input, Validate(input)), Report(input, err))
+	_, err = DecodeOptions{MaxArrayLength: 1}.Decode(input)
+	assert.Contains(Report(input, err), "1 | [1, x]\n  |     ^")
+
+	// anything else is just the message
+	assert.Equal("unexpected EOF", Report(input, errors.New("unexpected EOF")))
+	// and there is nothing to say about no error
+	assert.Equal("", Report(input, nil))
+	assert.Equal("", ErrorReport{Color: true}.Render(input, nil))
+
+	colored := ErrorReport{Color: true}.Render(input, Validate(input))
+	assert.Equal(strings.Join([]string{
+		"\x1b[1mUnknown value at 4\x1b[0m",
+		" \x1b[34m-->\x1b[0m line 1, column 5, at /1",
+		" \x1b[34m |\x1b[0m",
+		"\x1b[34m1 |\x1b[0m [1, x]",
+		"\x1b[34m  |\x1b[0m     \x1b[1;31m^\x1b[0m",
+	}, "\n"), colored)
+}
@@ -0,0 +1,400 @@
+package json
+
+import (
+	"io"
+	"strconv"
+)
+
+// TokenKind says what a Token is
+type TokenKind int
+
+// These are the kinds of tokens a Tokenizer returns
+const (
+	ObjectStart TokenKind = iota + 1
+	ObjectEnd
+	ArrayStart
+	ArrayEnd
+	Key
+	String
+	NumberLiteral
+	Bool
+	Null
+)
+
+var tokenKindNames = map[TokenKind]string{
+	ObjectStart:   "ObjectStart",
+	ObjectEnd:     "ObjectEnd",
+	ArrayStart:    "ArrayStart",
+	ArrayEnd:      "ArrayEnd",
+	Key:           "Key",
+	String:        "String",
+	NumberLiteral: "NumberLiteral",
+	Bool:          "Bool",
+	Null:          "Null",
+}
+
+func (kind TokenKind) String() string {
+	if name, ok := tokenKindNames[kind]; ok {
+		return name
+	}
+	return "TokenKind(" + strconv.Itoa(int(kind)) + ")"
+}
+
+// Token is a single piece of a json document
+type Token struct {
+	Kind TokenKind
+	// Raw is the token as it appears in the input, e.g. `"a\n"`, `1.5e3` or `true`.
+	// It points into the input so it should not be modified.
+	Raw []byte
+	// Text is the unquoted value of Key and String tokens
+	Text string
+	// Bool is the value of Bool tokens
+	Bool bool
+	// Offset is the position of the token in the input
+	Offset int
+}
+
+// what the tokenizer expects to read next
+type tokenizerState int
+
+const (
+	stateValue tokenizerState = iota
+	stateFirstArrayValue
+	stateFirstKey
+	stateKey
+	stateAfterValue
+	stateEnd
+)
+
+// Tokenizer walks a json document token by token, without building the maps and slices that Unmarshall does.
+// Validate and Unmarshall build on it, so it finds the same problems and reports them with the same ValidationError.
+type Tokenizer struct {
+	iter    *iterator
+	options *DecodeOptions
+	stack   []frame
+	state   tokenizerState
+	last    TokenKind
+	err     error
+	// nodes is the number of values read so far, for MaxNodes
+	nodes int
+	// stringStart is where the string being read started, or -1. If reading it fails, recover needs to know
+	stringStart int
+}
+
+// NewTokenizer returns a Tokenizer for the json document in data
+func NewTokenizer(data []byte) *Tokenizer {
+	return DecodeOptions{}.NewTokenizer(data)
+}
+
+// NewTokenizer returns a Tokenizer for the json document in data that uses these options
+func (opts DecodeOptions) NewTokenizer(data []byte) *Tokenizer {
+	return &Tokenizer{iter: &iterator{s: data}, options: &opts, stringStart: -1}
+}
+
+// Next returns the next token. At the end of the document it returns io.EOF.
+// Once it returns an error it keeps returning it.
+func (t *Tokenizer) Next() (Token, error) {
+	var token Token
+	err := t.read(&token)
+	return token, err
+}
+
+// read is Next for the parser, it fills in token rather than returning it since that is a lot of copying for every value
+func (t *Tokenizer) read(token *Token) error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.last == 0 && t.iter.reader == nil {
+		// a stream is checked as it is read, see checkStreamSize
+		t.err = checkInputSize(t.iter, t.options)
+		if t.err != nil {
+			return t.err
+		}
+	}
+	err := t.next(token)
+	if err != nil {
+		if err != io.EOF {
+			err = withHint(t.iter, err)
+		}
+		t.err = err
+		*token = Token{}
+		return err
+	}
+	t.last = token.Kind
+	return nil
+}
+
+// Skip jumps over the value whose start was the last token returned by Next.
+// After ObjectStart or ArrayStart, it reads up to and including the matching end.
+// After a Key, it reads the whole value of that key. Otherwise it does nothing.
+func (t *Tokenizer) Skip() error {
+	depth := 0
+	switch t.last {
+	case ObjectStart, ArrayStart:
+		depth = 1
+	case Key:
+		token, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if token.Kind != ObjectStart && token.Kind != ArrayStart {
+			return nil
+		}
+		depth = 1
+	default:
+		return nil
+	}
+	for depth > 0 {
+		token, err := t.Next()
+		if err != nil {
+			return err
+		}
+		switch token.Kind {
+		case ObjectStart, ArrayStart:
+			depth++
+		case ObjectEnd, ArrayEnd:
+			depth--
+		}
+	}
+	return nil
+}
+
+// next is the json grammar. Everything that reads json, down to Validate and Unmarshall, reads its tokens.
+func (t *Tokenizer) next(token *Token) error {
+	iter := t.iter
+	for {
+		iter.AdvancePastAllWhiteSpace()
+		switch t.state {
+		case stateEnd:
+			return io.EOF
+		case stateAfterValue:
+			if len(t.stack) == 0 {
+				if iter.HasNext() {
+					return withRule(newValidationError(iter, ErrTrailingData, iter.Cursor(), "end of input", "Extra characters at the end of the json string"), ruleJSONText)
+				}
+				t.state = stateEnd
+				return io.EOF
+			}
+			top := &t.stack[len(t.stack)-1]
+			if top.isObject && iter.Current() == '}' {
+				return t.end(token, ObjectEnd)
+			}
+			if !top.isObject && iter.Current() == ']' {
+				return t.end(token, ArrayEnd)
+			}
+			rule := containerRule(top.isObject)
+			err := expect(iter, ',', rule)
+			if err != nil {
+				return t.withPath(err, false)
+			}
+			top.index++
+			t.state = stateValue
+			if top.isObject {
+				t.state = stateKey
+			}
+			if !iter.HasNext() {
+				return t.withPath(expect(iter, closing(top.isObject), rule), false)
+			}
+			iter.AdvancePastAllWhiteSpace()
+			err = checkLength(iter, top.isObject, top.index, t.options)
+			if err != nil {
+				return t.withPath(err, false)
+			}
+		case stateFirstArrayValue:
+			if iter.Current() == ']' {
+				return t.end(token, ArrayEnd)
+			}
+			if !iter.HasNext() {
+				return t.withPath(expect(iter, ']', ruleArray), false)
+			}
+			t.state = stateValue
+		case stateFirstKey:
+			if iter.Current() == '}' {
+				return t.end(token, ObjectEnd)
+			}
+			if !iter.HasNext() {
+				return t.withPath(expect(iter, '}', ruleObject), false)
+			}
+			t.state = stateKey
+		case stateKey:
+			return t.key(token)
+		case stateValue:
+			err := t.value(token)
+			if err != nil {
+				return t.withPath(err, true)
+			}
+			return nil
+		}
+	}
+}
+
+func (t *Tokenizer) key(token *Token) error {
+	iter := t.iter
+	if iter.Current() != '"' {
+		return t.withPath(withRule(newValidationError(iter, ErrUnexpectedCharacter, iter.Cursor(), "a string key", "Was expecting a string key but got %q instead", iter.Current()), ruleMember), false)
+	}
+	keyStart := iter.Mark()
+	t.stringStart = keyStart
+	key, err := scanString(iter, t.options)
+	if err != nil {
+		return t.withPath(err, false)
+	}
+	t.stringStart = -1
+	raw := iter.SliceTillCursor(keyStart)
+	err = t.stack[len(t.stack)-1].setKey(iter, key, keyStart, t.options)
+	if err != nil {
+		return t.withPath(err, false)
+	}
+	err = expect(iter, ':', ruleMember)
+	if err != nil {
+		return t.withPath(err, false)
+	}
+	t.state = stateValue
+	*token = Token{Kind: Key, Raw: raw, Text: key, Offset: keyStart}
+	return nil
+}
+
+func (t *Tokenizer) value(token *Token) error {
+	iter := t.iter
+	err := checkNodes(iter, t.nodes, t.options)
+	if err != nil {
+		return err
+	}
+	t.nodes++
+	start := iter.Mark()
+	*token = Token{Offset: start}
+	switch char := iter.Current(); {
+	case char == '[' || char == '{':
+		err = checkDepth(iter, len(t.stack), t.options)
+		if err != nil {
+			return err
+		}
+		iter.Next()
+		t.stack = append(t.stack, frame{isObject: char == '{'})
+		t.state = stateFirstArrayValue
+		token.Kind = ArrayStart
+		if char == '{' {
+			t.state = stateFirstKey
+			token.Kind = ObjectStart
+		}
+	case char == '"':
+		token.Kind = String
+		t.stringStart = start
+		token.Text, err = scanString(iter, t.options)
+		if err == nil {
+			t.stringStart = -1
+		}
+	case char == 'n':
+		token.Kind = Null
+		err = scanLiteral(iter, "null", t.options)
+	case char == 't':
+		token.Kind = Bool
+		token.Bool = true
+		err = scanLiteral(iter, "true", t.options)
+	case char == 'f':
+		token.Kind = Bool
+		err = scanLiteral(iter, "false", t.options)
+	case isNumber(iter):
+		token.Kind = NumberLiteral
+		err = scanNumber(iter, t.options)
+	default:
+		return withRule(newValidationError(iter, ErrUnexpectedCharacter, iter.Cursor(), "a value", "Unknown value at %d", iter.Cursor()), ruleValue)
+	}
+	if err != nil {
+		return err
+	}
+	token.Raw = iter.SliceTillCursor(start)
+	if token.Kind != ArrayStart && token.Kind != ObjectStart {
+		t.state = stateAfterValue
+	}
+	return nil
+}
+
+// recover is called after Next returns an error, to carry on from the next place that makes sense.
+// It skips to the next , ] or } of the object or array the error was in, jumping over any strings,
+// arrays and objects on the way, so that the next call to Next reads what comes after it.
+// It returns false if there is nowhere to carry on from.
+func (t *Tokenizer) recover() bool {
+	iter := t.iter
+	t.err = nil
+	if t.stringStart >= 0 {
+		// the error was in the middle of a string so start again from its beginning to skip all of it
+		iter.cursor = t.stringStart
+		t.stringStart = -1
+	}
+	if iter.Current() == ',' && len(t.stack) > 0 && (t.state == stateKey || t.state == stateValue && !t.stack[len(t.stack)-1].isObject) {
+		// a comma where a key or an array value should start is one too many, like in {,} or [1,,2],
+		// so skip it and carry on reading what was expected. Stopping on it would read it again as a separator.
+		iter.Next()
+		if top := t.stack[len(t.stack)-1]; top.index == 0 {
+			t.state = stateFirstArrayValue
+			if top.isObject {
+				t.state = stateFirstKey
+			}
+		}
+		return true
+	}
+	// depth counts the arrays and objects we are skipping over
+	depth := 0
+	for iter.HasNext() {
+		switch iter.Current() {
+		case '"':
+			skipString(iter)
+			continue
+		case '[', '{':
+			depth++
+		case ']', '}', ',':
+			if depth > 0 {
+				if iter.Current() != ',' {
+					depth--
+				}
+				break
+			}
+			if len(t.stack) == 0 {
+				return false
+			}
+			if iter.Current() == ',' {
+				t.state = stateAfterValue
+				return true
+			}
+			// a } for an array or a ] for an object closes the ones in between too, or is just skipped
+			isObject := iter.Current() == '}'
+			for i := len(t.stack) - 1; i >= 0; i-- {
+				if t.stack[i].isObject == isObject {
+					t.stack = t.stack[:i+1]
+					t.state = stateAfterValue
+					return true
+				}
+			}
+		}
+		iter.Next()
+	}
+	return false
+}
+
+// skipString moves past the string that starts at the cursor, without checking it
+func skipString(iter *iterator) {
+	iter.Next()
+	for iter.HasNext() && iter.Current() != '"' {
+		if iter.Current() == '\\' {
+			iter.Next()
+		}
+		iter.Next()
+	}
+	iter.Next()
+}
+
+// end closes the innermost object or array
+func (t *Tokenizer) end(token *Token, kind TokenKind) error {
+	start := t.iter.Mark()
+	t.iter.Next()
+	t.stack = t.stack[:len(t.stack)-1]
+	t.state = stateAfterValue
+	*token = Token{Kind: kind, Raw: t.iter.SliceTillCursor(start), Offset: start}
+	return nil
+}
+
+// withPath fills in the Path of a ValidationError from the stack, the way the parser does
+func (t *Tokenizer) withPath(err error, inValue bool) error {
+	return withFramePath(err, t.stack, inValue)
+}
@@ -0,0 +1,104 @@
+package json
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer(t *testing.T) {
+	assert := assert.New(t)
+	tokenizer := NewTokenizer([]byte(` {"k1": ["v\n1", -1.5e3, true, false, null, [], {}], "k2": {"k3": "v3"}} `))
+	expected := []Token{
+		{Kind: ObjectStart, Raw: []byte(`{`), Offset: 1},
+		{Kind: Key, Raw: []byte(`"k1"`), Text: "k1", Offset: 2},
+		{Kind: ArrayStart, Raw: []byte(`[`), Offset: 8},
+		{Kind: String, Raw: []byte(`"v\n1"`), Text: "v\n1", Offset: 9},
+		{Kind: NumberLiteral, Raw: []byte(`-1.5e3`), Offset: 17},
+		{Kind: Bool, Raw: []byte(`true`), Bool: true, Offset: 25},
+		{Kind: Bool, Raw: []byte(`false`), Offset: 31},
+		{Kind: Null, Raw: []byte(`null`), Offset: 38},
+		{Kind: ArrayStart, Raw: []byte(`[`), Offset: 44},
+		{Kind: ArrayEnd, Raw: []byte(`]`), Offset: 45},
+		{Kind: ObjectStart, Raw: []byte(`{`), Offset: 48},
+		{Kind: ObjectEnd, Raw: []byte(`}`), Offset: 49},
+		{Kind: ArrayEnd, Raw: []byte(`]`), Offset: 50},
+		{Kind: Key, Raw: []byte(`"k2"`), Text: "k2", Offset: 53},
+		{Kind: ObjectStart, Raw: []byte(`{`), Offset: 59},
+		{Kind: Key, Raw: []byte(`"k3"`), Text: "k3", Offset: 60},
+		{Kind: String, Raw: []byte(`"v3"`), Text: "v3", Offset: 66},
+		{Kind: ObjectEnd, Raw: []byte(`}`), Offset: 70},
+		{Kind: ObjectEnd, Raw: []byte(`}`), Offset: 71},
+	}
+	for _, expectedToken := range expected {
+		token, err := tokenizer.Next()
+		assert.Nil(err)
+		assert.Equal(expectedToken, token)
+	}
+	_, err := tokenizer.Next()
+	assert.Equal(io.EOF, err)
+}
+
+func TestTokenizerSkip(t *testing.T) {
+	assert := assert.New(t)
+	tokenizer := NewTokenizer([]byte(`{"skip": {"a": [1, {"b": 2}]}, "keep": [1, [2, 3], 4], "last": 5}`))
+	var kinds []TokenKind
+	for {
+		token, err := tokenizer.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(err)
+		kinds = append(kinds, token.Kind)
+		switch {
+		case token.Kind == Key && token.Text == "skip":
+			assert.Nil(tokenizer.Skip())
+		case token.Kind == ArrayStart && token.Offset == 43:
+			assert.Nil(tokenizer.Skip())
+		case token.Kind == Key && token.Text == "last":
+			assert.Nil(tokenizer.Skip())
+		}
+	}
+	assert.Equal([]TokenKind{ObjectStart, Key, Key, ArrayStart, NumberLiteral, ArrayStart, NumberLiteral, ArrayEnd, Key, ObjectEnd}, kinds)
+}
+
+func TestTokenizerErrorsMatchValidate(t *testing.T) {
+	assert := assert.New(t)
+	inputs := []string{
+		``,
+		`x`,
+		`"k1`,
+		`[`,
+		`[1,`,
+		`[1, `,
+		`[1 2]`,
+		`[[1 2]]`,
+		`{`,
+		`{"k1"`,
+		`{"k1": 1,`,
+		`{"k1": 1, }`,
+		`{"k1": 1 "k2"}`,
+		`{1234: true}`,
+		`{"items": [{}, {}, {}, {"name": tru}]}`,
+		`{"a/b~c": [-]}`,
+		`{"k1": [1.]}`,
+		"[\n\"héllo\" x]",
+		`1 2`,
+	}
+	for _, input := range inputs {
+		t.Run(
+			input,
+			func(t *testing.T) {
+				tokenizer := NewTokenizer([]byte(input))
+				var err error
+				for err == nil {
+					_, err = tokenizer.Next()
+				}
+				assert.Equal(Validate([]byte(input)), err)
+				_, again := tokenizer.Next()
+				assert.Equal(err, again)
+			},
+		)
+	}
+}
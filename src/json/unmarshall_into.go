@@ -0,0 +1,432 @@
+package json
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// UnmarshallTypeError is returned by UnmarshallInto when a json value can't be stored in the Go value it maps to.
+type UnmarshallTypeError struct {
+	// Value describes the json value, e.g. "string" or "number 1.5"
+	Value string
+	// Type is the Go type the value could not be stored in
+	Type reflect.Type
+	// Path is the JSON Pointer (RFC 6901) of the value
+	Path string
+	// Field is the Go struct field the value was meant for, starting from the outermost struct e.g. Order.Items.Name
+	// It is empty if the value is not stored in a struct field.
+	Field string
+}
+
+func (e *UnmarshallTypeError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("Cannot unmarshall %s at %q into the Go field %s of type %s", e.Value, e.Path, e.Field, e.Type)
+	}
+	return fmt.Sprintf("Cannot unmarshall %s at %q into a Go value of type %s", e.Value, e.Path, e.Type)
+}
+
+// UnmarshallInto loads data into the value v points to.
+// Json objects can be stored in structs and in maps with string or integer keys, json arrays in slices and arrays
+// and everything else in a Go value of the matching kind. Pointers are allocated as needed.
+// Struct fields are matched to keys by their `json:"name"` tag or by their name, ignoring case if there is no exact match.
+// Keys that don't match any field are ignored. Objects are read in the order of their keys, so if several keys match
+// the same field the last exact match wins, or the last case-insensitive one if none of them match exactly,
+// and the error returned is the first one in the document.
+func UnmarshallInto(data []byte, v any) error {
+	return DecodeOptions{}.UnmarshallInto(data, v)
+}
+
+// UnmarshallInto is UnmarshallInto using these options
+func (opts DecodeOptions) UnmarshallInto(data []byte, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("UnmarshallInto needs a non-nil pointer but got %T", v)
+	}
+	// objects are always decoded as *OrderedObject so they are read in the order of the document
+	ordered := opts
+	ordered.OrderedObjects = true
+	value, err := ordered.Decode(data)
+	if err != nil {
+		return err
+	}
+	return populate(target.Elem(), value, "", "", &opts)
+}
+
+// populate stores the decoded value in target. path and goField are only used for errors
+func populate(target reflect.Value, value any, path string, goField string, options *DecodeOptions) error {
+	if value, ok := value.(*OrderedObject); ok {
+		// the object goes into an OrderedObject as it is, rather than into its unexported fields
+		switch target.Type() {
+		case orderedObjectType:
+			target.Set(reflect.ValueOf(value))
+			return nil
+		case orderedObjectType.Elem():
+			target.Set(reflect.ValueOf(value).Elem())
+			return nil
+		}
+	}
+	switch target.Type() {
+	case numberType, bigIntType, bigFloatType, decimalType:
+		if literal, ok := numberLiteral(value); ok {
+			return populateBigNumber(target, literal, &UnmarshallTypeError{Value: describeValue(value), Type: target.Type(), Path: path, Field: goField})
+		}
+	}
+	if target.Kind() == reflect.Ptr {
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return populate(target.Elem(), value, path, goField, options)
+	}
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		if !options.OrderedObjects {
+			value = unordered(value)
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	typeErr := &UnmarshallTypeError{Value: describeValue(value), Type: target.Type(), Path: path, Field: goField}
+	switch value := value.(type) {
+	case nil:
+		// like Go, null only means something for the types that can be nil. Everything else is left alone
+		switch target.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Interface:
+			target.Set(reflect.Zero(target.Type()))
+		}
+	case bool:
+		if target.Kind() != reflect.Bool {
+			return typeErr
+		}
+		target.SetBool(value)
+	case string:
+		// a Number is a string too but only one that is a number can go in it
+		if target.Kind() != reflect.String || target.Type() == numberType && !isNumberLiteral(value) {
+			return typeErr
+		}
+		target.SetString(value)
+	case int64:
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if target.OverflowInt(value) {
+				return typeErr
+			}
+			target.SetInt(value)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if value < 0 || target.OverflowUint(uint64(value)) {
+				return typeErr
+			}
+			target.SetUint(uint64(value))
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(float64(value))
+		default:
+			return typeErr
+		}
+	case float64:
+		switch target.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if target.OverflowFloat(value) {
+				return typeErr
+			}
+			target.SetFloat(value)
+		default:
+			return typeErr
+		}
+	case []any:
+		return populateArray(target, value, path, goField, typeErr, options)
+	case *OrderedObject:
+		return populateObject(target, value, path, goField, typeErr, options)
+	case Number, *big.Int, *big.Float, Decimal:
+		literal, _ := numberLiteral(value)
+		return populateNumber(target, literal, typeErr)
+	default:
+		return typeErr
+	}
+	return nil
+}
+
+// populateNumber stores numbers decoded with a NumberPolicy other than Int64OrFloat64
+func populateNumber(target reflect.Value, value Number, typeErr error) error {
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := value.Int64()
+		if err != nil || target.OverflowInt(n) {
+			return typeErr
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil || target.OverflowUint(n) {
+			return typeErr
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := value.Float64()
+		if err != nil || target.OverflowFloat(f) {
+			return typeErr
+		}
+		target.SetFloat(f)
+	default:
+		return typeErr
+	}
+	return nil
+}
+
+// populateBigNumber stores any number in a Number, *big.Int, *big.Float or Decimal
+func populateBigNumber(target reflect.Value, literal Number, typeErr error) error {
+	switch target.Type() {
+	case numberType:
+		target.SetString(string(literal))
+	case bigIntType:
+		i, err := literal.BigInt()
+		if err != nil {
+			return typeErr
+		}
+		target.Set(reflect.ValueOf(i))
+	case decimalType:
+		d, err := ParseDecimal(string(literal))
+		if err != nil {
+			return typeErr
+		}
+		target.Set(reflect.ValueOf(d))
+	default:
+		f, err := parseBigNumber([]byte(literal), true)
+		if err != nil {
+			return typeErr
+		}
+		target.Set(reflect.ValueOf(f))
+	}
+	return nil
+}
+
+// numberLiteral turns any of the Go values a json number can be decoded as back into a literal
+func numberLiteral(value any) (Number, bool) {
+	switch value := value.(type) {
+	case int64:
+		return Number(strconv.FormatInt(value, 10)), true
+	case float64:
+		return Number(strconv.FormatFloat(value, 'g', -1, 64)), true
+	case Number:
+		return value, true
+	case *big.Int:
+		return Number(value.String()), true
+	case *big.Float:
+		return Number(value.Text('g', -1)), true
+	case Decimal:
+		return Number(value.String()), true
+	}
+	return "", false
+}
+
+func populateArray(target reflect.Value, array []any, path string, goField string, typeErr error, options *DecodeOptions) error {
+	switch target.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(target.Type(), len(array), len(array))
+		for i, item := range array {
+			err := populate(slice.Index(i), item, path+"/"+strconv.Itoa(i), goField, options)
+			if err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+	case reflect.Array:
+		// extra items are dropped and missing ones are zeroed
+		for i := 0; i < target.Len(); i++ {
+			if i >= len(array) {
+				target.Index(i).Set(reflect.Zero(target.Type().Elem()))
+				continue
+			}
+			err := populate(target.Index(i), array[i], path+"/"+strconv.Itoa(i), goField, options)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return typeErr
+	}
+	return nil
+}
+
+func populateObject(target reflect.Value, object *OrderedObject, path string, goField string, typeErr error, options *DecodeOptions) error {
+	switch target.Kind() {
+	case reflect.Map:
+		keyType := target.Type().Key()
+		if !isMapKeyKind(keyType.Kind()) {
+			return typeErr
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		for _, key := range object.keys {
+			item := object.values[key]
+			itemPath := path + "/" + pointerEscaper.Replace(key)
+			mapKey, ok := parseMapKey(key, keyType)
+			if !ok {
+				return &UnmarshallTypeError{Value: fmt.Sprintf("object key %q", key), Type: keyType, Path: itemPath, Field: goField}
+			}
+			elem := reflect.New(target.Type().Elem()).Elem()
+			err := populate(elem, item, itemPath, goField, options)
+			if err != nil {
+				return err
+			}
+			target.SetMapIndex(mapKey, elem)
+		}
+	case reflect.Struct:
+		fields := cachedTypeFields(target.Type())
+		prefix := goField
+		if prefix == "" {
+			prefix = target.Type().Name()
+		}
+		// exact has the fields set by a key that matches exactly, case-insensitive matches don't replace those
+		var exact map[*field]bool
+		for _, key := range object.keys {
+			item := object.values[key]
+			f := lookupField(fields, key)
+			if f == nil {
+				continue
+			}
+			if f.name == key {
+				if exact == nil {
+					exact = make(map[*field]bool)
+				}
+				exact[f] = true
+			} else if exact[f] {
+				continue
+			}
+			itemPath := path + "/" + pointerEscaper.Replace(key)
+			fieldName := prefix + "." + f.goName
+			fieldValue, ok := fieldByIndex(target, f.index)
+			if !ok {
+				return fmt.Errorf("Cannot set %s because it is promoted through a nil pointer to an unexported struct", fieldName)
+			}
+			if f.quoted {
+				unquoted, ok := unquoteValue(item)
+				if !ok {
+					return &UnmarshallTypeError{Value: describeValue(item), Type: f.typ, Path: itemPath, Field: fieldName}
+				}
+				item = unquoted
+			}
+			err := populate(fieldValue, item, itemPath, fieldName, options)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return typeErr
+	}
+	return nil
+}
+
+// unordered turns the *OrderedObject in value into map[string]any, for when OrderedObjects wasn't asked for
+func unordered(value any) any {
+	switch value := value.(type) {
+	case *OrderedObject:
+		object := make(map[string]any, len(value.keys))
+		for key, item := range value.values {
+			object[key] = unordered(item)
+		}
+		return object
+	case []any:
+		for i, item := range value {
+			value[i] = unordered(item)
+		}
+	}
+	return value
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex but it allocates the nil embedded pointers it walks through
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isMapKeyKind reports if maps with keys of this kind can be read from and written to json objects
+func isMapKeyKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func parseMapKey(key string, keyType reflect.Type) (reflect.Value, bool) {
+	mapKey := reflect.New(keyType).Elem()
+	switch keyType.Kind() {
+	case reflect.String:
+		mapKey.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || mapKey.OverflowInt(n) {
+			return mapKey, false
+		}
+		mapKey.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || mapKey.OverflowUint(n) {
+			return mapKey, false
+		}
+		mapKey.SetUint(n)
+	}
+	return mapKey, true
+}
+
+// unquoteValue handles the `string` tag option where a scalar is written inside a json string e.g. "123"
+func unquoteValue(value any) (any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	str, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+	unquoted, err := Decode([]byte(str))
+	if err != nil {
+		return nil, false
+	}
+	switch unquoted.(type) {
+	case []any, map[string]any:
+		return nil, false
+	}
+	return unquoted, true
+}
+
+// describeValue is used to fill in UnmarshallTypeError.Value
+func describeValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprintf("bool %v", value)
+	case string:
+		return "string"
+	case int64, float64, Number, *big.Int, *big.Float, Decimal:
+		return fmt.Sprintf("number %v", value)
+	case []any:
+		return "array"
+	case map[string]any, *OrderedObject:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
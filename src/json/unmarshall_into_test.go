@@ -0,0 +1,196 @@
+package json
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID      int64  `json:"id"`
+	Created string `json:"created,omitempty"`
+}
+
+type Extra struct {
+	Note string
+}
+
+type Item struct {
+	Name     string  `json:"name"`
+	Quantity uint8   `json:"quantity"`
+	Price    float64 `json:"price,string"`
+}
+
+type Order struct {
+	Base
+	*Extra
+	Items    []Item         `json:"items"`
+	Tags     [2]string      `json:"tags"`
+	Counts   map[string]int `json:"counts"`
+	ByID     map[int]*Item  `json:"by_id"`
+	Customer *string        `json:"customer"`
+	Paid     bool           `json:"paid,string"`
+	Meta     any            `json:"meta"`
+	Ignored  string         `json:"-"`
+	Dash     string         `json:"-,"`
+	private  string
+	Nested   map[string][]bool `json:"nested"`
+}
+
+func TestUnmarshallInto(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`{
+		"id": 7,
+		"Note": "leave at the door",
+		"items": [{"name": "apple", "quantity": 3, "price": "0.5"}, {"NAME": "pear", "price": null}],
+		"tags": ["a", "b", "c"],
+		"counts": {"x": 1},
+		"by_id": {"12": {"name": "fig"}},
+		"customer": "ope",
+		"paid": "true",
+		"meta": {"k": [1.5]},
+		"Ignored": "v",
+		"-": "dash",
+		"private": "v",
+		"unknown": {"k": "v"},
+		"nested": {"k": [true, false]}
+	}`)
+	var order Order
+	err := UnmarshallInto(input, &order)
+	assert.Nil(err)
+
+	customer := "ope"
+	assert.Equal(Order{
+		Base:     Base{ID: 7},
+		Extra:    &Extra{Note: "leave at the door"},
+		Items:    []Item{{Name: "apple", Quantity: 3, Price: 0.5}, {Name: "pear"}},
+		Tags:     [2]string{"a", "b"},
+		Counts:   map[string]int{"x": 1},
+		ByID:     map[int]*Item{12: {Name: "fig"}},
+		Customer: &customer,
+		Paid:     true,
+		Meta:     map[string]any{"k": []any{1.5}},
+		Dash:     "dash",
+		Nested:   map[string][]bool{"k": {true, false}},
+	}, order)
+}
+
+func TestUnmarshallIntoScalars(t *testing.T) {
+	assert := assert.New(t)
+
+	var number float32
+	assert.Nil(UnmarshallInto([]byte(`12`), &number))
+	assert.Equal(float32(12), number)
+
+	var pointer **int
+	assert.Nil(UnmarshallInto([]byte(`12`), &pointer))
+	assert.Equal(12, **pointer)
+	assert.Nil(UnmarshallInto([]byte(`null`), &pointer))
+	assert.Nil(pointer)
+
+	var array []int
+	assert.Nil(UnmarshallInto([]byte(`[1, 2]`), &array))
+	assert.Equal([]int{1, 2}, array)
+
+	assert.Error(UnmarshallInto([]byte(`[1, 2]`), array))
+	assert.Error(UnmarshallInto([]byte(`[1, 2]`), nil))
+	assert.IsType(&SyntaxError{}, UnmarshallInto([]byte(`[1, 2`), &array))
+
+	var numberField struct{ X Number }
+	assert.Nil(UnmarshallInto([]byte(`{"X": "-1.5e3"}`), &numberField))
+	assert.Equal(Number("-1.5e3"), numberField.X)
+	for _, input := range []string{`{"X": "abc"}`, `{"X": ""}`, `{"X": "1 "}`} {
+		assert.IsType(&UnmarshallTypeError{}, UnmarshallInto([]byte(input), &numberField), input)
+	}
+}
+
+func TestUnmarshallIntoTypeErrors(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected UnmarshallTypeError
+	}{
+		{"string into int", []byte(`{"id": "7"}`), UnmarshallTypeError{Value: "string", Path: "/id", Field: "Order.ID"}},
+		{"float into uint8", []byte(`{"items": [{}, {}, {}, {"quantity": 1.5}]}`), UnmarshallTypeError{Value: "number 1.5", Path: "/items/3/quantity", Field: "Order.Items.Quantity"}},
+		{"overflow", []byte(`{"items": [{"quantity": 256}]}`), UnmarshallTypeError{Value: "number 256", Path: "/items/0/quantity", Field: "Order.Items.Quantity"}},
+		{"bad quoted value", []byte(`{"items": [{"price": "abc"}]}`), UnmarshallTypeError{Value: "string", Path: "/items/0/price", Field: "Order.Items.Price"}},
+		{"quoted value that is not a string", []byte(`{"paid": true}`), UnmarshallTypeError{Value: "bool true", Path: "/paid", Field: "Order.Paid"}},
+		{"bad map key", []byte(`{"by_id": {"a/b": {}}}`), UnmarshallTypeError{Value: `object key "a/b"`, Path: "/by_id/a~1b", Field: "Order.ByID"}},
+		{"object into array", []byte(`{"tags": {}}`), UnmarshallTypeError{Value: "object", Path: "/tags", Field: "Order.Tags"}},
+		{"array into struct", []byte(`[]`), UnmarshallTypeError{Value: "array", Path: "", Field: ""}},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				var order Order
+				err, ok := UnmarshallInto(testcase.input, &order).(*UnmarshallTypeError)
+				if !assert.True(ok) {
+					return
+				}
+				err.Type = nil
+				assert.Equal(testcase.expected, *err)
+			},
+		)
+	}
+}
+
+func TestTypeFields(t *testing.T) {
+	assert := assert.New(t)
+	type Inner struct {
+		A string
+		B string `json:"B"`
+		C string
+	}
+	type Other struct {
+		B string
+		C string
+	}
+	type Outer struct {
+		Inner
+		Other
+		A string
+	}
+	var names []string
+	for _, f := range typeFields(reflect.TypeOf(Outer{})) {
+		names = append(names, f.name)
+	}
+	// A is hidden by the less nested Outer.A, the tagged B wins and C is ambiguous
+	assert.Equal([]string{"B", "A"}, names)
+}
+
+func TestUnmarshallIntoKeyOrder(t *testing.T) {
+	assert := assert.New(t)
+	type Named struct {
+		Name string
+		N    int
+	}
+	// run each a few times since a map would visit the keys in a different order each time
+	for i := 0; i < 20; i++ {
+		var named Named
+		assert.Nil(UnmarshallInto([]byte(`{"Name": "a", "name": "b", "NAME": "c"}`), &named))
+		assert.Equal("a", named.Name)
+		assert.Nil(UnmarshallInto([]byte(`{"name": "b", "NAME": "c"}`), &named))
+		assert.Equal("c", named.Name)
+
+		err, ok := UnmarshallInto([]byte(`{"Name": 1, "N": "x"}`), &named).(*UnmarshallTypeError)
+		if assert.True(ok) {
+			assert.Equal("/Name", err.Path)
+		}
+		var counts map[string]int
+		err, ok = UnmarshallInto([]byte(`{"a": "x", "b": "y", "c": "z"}`), &counts).(*UnmarshallTypeError)
+		if assert.True(ok) {
+			assert.Equal("/a", err.Path)
+		}
+	}
+
+	var meta struct{ Meta any }
+	assert.Nil(DecodeOptions{OrderedObjects: true}.UnmarshallInto([]byte(`{"Meta": {"b": 1, "a": [{"d": 2, "c": 3}]}}`), &meta))
+	if assert.IsType(&OrderedObject{}, meta.Meta) {
+		assert.Equal([]string{"b", "a"}, meta.Meta.(*OrderedObject).Keys())
+		items, _ := meta.Meta.(*OrderedObject).Get("a")
+		assert.Equal([]string{"d", "c"}, items.([]any)[0].(*OrderedObject).Keys())
+	}
+}
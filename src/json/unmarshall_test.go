@@ -27,9 +27,8 @@ func TestUnmarshallLiteral(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallLiteral(iter, testcase.literal, testcase.value)
-				assert.Equal(testcase.value, output, "Expected UnmarshallKeyword(%v, %v, %v) to be %v but got %v", iter, testcase.literal, testcase.value, testcase.value, output)
+				output := Unmarshall(testcase.input)
+				assert.Equal(testcase.value, output, "Expected UnmarshallKeyword(%v, %v, %v) to be %v but got %v", testcase.input, testcase.literal, testcase.value, testcase.value, output)
 			},
 		)
 	}
@@ -56,9 +55,8 @@ func TestUnmarshallString(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallString(iter)
-				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallNumber(%v) to be %v but got %v", iter, testcase.expectedOutput, output)
+				output := Unmarshall(testcase.input)
+				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallNumber(%v) to be %v but got %v", testcase.input, testcase.expectedOutput, output)
 			},
 		)
 
@@ -69,7 +67,6 @@ func TestUnmarshallString(t *testing.T) {
 func TestUnmarshallNumber(t *testing.T) {
 	testCases := []TestCase{
 		{"Float with 0 decimal", []byte(`123.0`), 123.0},
-		{"Float with + sign", []byte(`+123.0`), 123.0},
 		{"Float with - sign", []byte(`-123.0`), -123.0},
 		{"Float with decimals", []byte(`-123.123`), -123.123},
 		{"Float that's a decimal fraction", []byte(`0.234`), 0.234},
@@ -81,10 +78,9 @@ func TestUnmarshallNumber(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallNumber(iter)
+				output := Unmarshall(testcase.input)
 				if !floatEquals(output.(float64), testcase.expectedOutput.(float64)) {
-					t.Errorf("Expected UnmarshallNumber(%v) to be %v but got %v", iter, testcase.expectedOutput, output)
+					t.Errorf("Expected UnmarshallNumber(%v) to be %v but got %v", testcase.input, testcase.expectedOutput, output)
 				}
 			},
 		)
@@ -100,8 +96,7 @@ func TestUnmarshallNumber(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallNumber(iter)
+				output := Unmarshall(testcase.input)
 				assert.Equal(testcase.expectedOutput, output)
 			},
 		)
@@ -122,9 +117,8 @@ func TestUnmarshallArray(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallArray(iter)
-				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallArray(%v) to be %v but got %v", iter, testcase.expectedOutput, output)
+				output := Unmarshall(testcase.input)
+				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallArray(%v) to be %v but got %v", testcase.input, testcase.expectedOutput, output)
 			},
 		)
 	}
@@ -142,9 +136,64 @@ func TestUnmarshallObject(t *testing.T) {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				iter := &iterator{s: testcase.input}
-				output := unmarshallObject(iter)
-				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallObject(%v) to be %v but got %v", iter, testcase.expectedOutput, output)
+				output := Unmarshall(testcase.input)
+				assert.Equal(testcase.expectedOutput, output, "Expected UnmarshallObject(%v) to be %v but got %v", testcase.input, testcase.expectedOutput, output)
+			},
+		)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []TestCase{
+		{"Array with spaces", []byte(` [ "v1" , 2 ] `), []any{"v1", int64(2)}},
+		{"Empty array with space", []byte(`[ ]`), make([]any, 0)},
+		{"Empty object with space", []byte(`{ }`), make(map[string]any, 0)},
+		{"Array with empty strings", []byte(`["", ""]`), []any{"", ""}},
+		{"Object", []byte(`{"k1": [true, null]}`), map[string]any{"k1": []any{true, nil}}},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				output, err := Decode(testcase.input)
+				assert.Nil(err)
+				assert.Equal(testcase.expectedOutput, output)
+			},
+		)
+	}
+
+	invalidCases := []struct {
+		name   string
+		input  []byte
+		offset int
+	}{
+		{"Empty input", []byte(``), 0},
+		{"Unknown value", []byte(`[1, x]`), 4},
+		{"Bad literal", []byte(`nul`), 3},
+		{"Missing comma in array", []byte(`[1 2]`), 3},
+		{"Trailing comma in array", []byte(`[1,]`), 3},
+		{"Unterminated array", []byte(`["v1"`), 5},
+		{"Key that is not a string", []byte(`{1: 2}`), 1},
+		{"Missing colon", []byte(`{"k1" 2}`), 6},
+		{"Missing comma in object", []byte(`{"k1": 1 "k2": 2}`), 9},
+		{"Unterminated object", []byte(`{"k1": 1`), 8},
+		{"Unterminated string", []byte(`"abc`), 4},
+		{"Bad number", []byte(`-`), 1},
+		{"Trailing characters", []byte(`"v1" "v2"`), 5},
+	}
+	for _, testcase := range invalidCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				var output any
+				var err error
+				assert.NotPanics(func() { output, err = Decode(testcase.input) })
+				assert.Nil(output)
+				syntaxErr, ok := err.(*SyntaxError)
+				if assert.True(ok, "Expected a *SyntaxError but got %#v", err) {
+					assert.Equal(testcase.offset, syntaxErr.Offset)
+				}
 			},
 		)
 	}
@@ -0,0 +1,176 @@
+package json
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// scanString reads a string and returns it unquoted, following the rules in RFC 8259:
+// the only escapes are \" \\ \/ \b \f \n \r \t and \uXXXX, where two \uXXXX escapes for
+// a UTF-16 surrogate pair make a single rune, and control characters have to be escaped.
+// Bytes that are not valid UTF-8 are handled according to options.InvalidUTF8.
+func scanString(iter *iterator, options *DecodeOptions) (string, error) {
+	start := iter.Mark()
+	err := expect(iter, '"', ruleString)
+	if err != nil {
+		return "", err
+	}
+	// unquoted is only used once we see an escape, until then the string is just the bytes between the quotes
+	var unquoted []byte
+	contentStart := iter.Cursor()
+	runStart := contentStart
+	for iter.HasNext() && iter.Current() != '"' {
+		if options.MaxStringLength > 0 && iter.Cursor()-contentStart >= options.MaxStringLength {
+			return "", newLimitError(iter, start, StringLengthLimit, options.MaxStringLength, "bytes", "Strings can't be longer than %d bytes")
+		}
+		char := iter.Current()
+		if char < 0x20 {
+			return "", withRule(newValidationError(iter, ErrInvalidString, iter.Cursor(), "an escaped control character", "Control characters need to be escaped in strings"), ruleChar)
+		}
+		if char >= utf8.RuneSelf {
+			r, size := iter.CurrentRune()
+			if r != utf8.RuneError || size != 1 {
+				for i := 0; i < size; i++ {
+					iter.Next()
+				}
+				continue
+			}
+			switch options.InvalidUTF8 {
+			case RejectInvalidUTF8:
+				return "", newValidationError(iter, ErrInvalidString, iter.Cursor(), "valid UTF-8", "Invalid UTF-8 byte %#x in string", char)
+			case ReplaceInvalidUTF8:
+				if unquoted == nil {
+					unquoted = make([]byte, 0, iter.Cursor()-contentStart+16)
+				}
+				unquoted = append(unquoted, iter.Slice(runStart, iter.Cursor())...)
+				unquoted = appendRune(unquoted, utf8.RuneError)
+				iter.Next()
+				runStart = iter.Cursor()
+			default:
+				iter.Next()
+			}
+			continue
+		}
+		if char != '\\' {
+			iter.Next()
+			continue
+		}
+		escapeStart := iter.Cursor()
+		if unquoted == nil {
+			unquoted = make([]byte, 0, escapeStart-contentStart+16)
+		}
+		unquoted = append(unquoted, iter.Slice(runStart, escapeStart)...)
+		iter.Next()
+		switch iter.Current() {
+		case '"', '\\', '/':
+			unquoted = append(unquoted, iter.Current())
+		case 'b':
+			unquoted = append(unquoted, '\b')
+		case 'f':
+			unquoted = append(unquoted, '\f')
+		case 'n':
+			unquoted = append(unquoted, '\n')
+		case 'r':
+			unquoted = append(unquoted, '\r')
+		case 't':
+			unquoted = append(unquoted, '\t')
+		case 'u':
+			unquoted, err = appendUnicodeEscape(iter, unquoted, escapeStart, options)
+			if err != nil {
+				return "", err
+			}
+			runStart = iter.Cursor()
+			continue
+		default:
+			return "", withRule(newValidationError(iter, ErrInvalidEscape, escapeStart, "a valid escape sequence", "Invalid escape sequence %q in string", iter.Slice(escapeStart, escapeStart+2)), ruleChar)
+		}
+		iter.Next()
+		runStart = iter.Cursor()
+	}
+	end := iter.Cursor()
+	err = expect(iter, '"', ruleString)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if unquoted == nil {
+		s = string(iter.Slice(contentStart, end))
+	} else {
+		s = string(append(unquoted, iter.Slice(runStart, end)...))
+	}
+	// this is only to clear the mark
+	iter.SliceTillCursor(start)
+	return s, nil
+}
+
+// appendUnicodeEscape is called with the cursor on the u of \uXXXX and leaves it just after the escape.
+// If the escape is the first half of a surrogate pair, it reads the second half too.
+func appendUnicodeEscape(iter *iterator, unquoted []byte, escapeStart int, options *DecodeOptions) ([]byte, error) {
+	r, err := scanHex(iter, escapeStart)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if !utf16.IsSurrogate(r) {
+			return appendRune(unquoted, r), nil
+		}
+		// a high surrogate followed by a \u escape for a low surrogate is a single rune
+		if r >= 0xdc00 || iter.Current() != '\\' || iter.Peek(1) != 'u' {
+			return appendLoneSurrogate(iter, unquoted, r, escapeStart, options)
+		}
+		secondStart := iter.Cursor()
+		iter.Next()
+		second, err := scanHex(iter, secondStart)
+		if err != nil {
+			return nil, err
+		}
+		if combined := utf16.DecodeRune(r, second); combined != utf8.RuneError {
+			return appendRune(unquoted, combined), nil
+		}
+		// the first one is on its own but the second one could still be the start of a pair
+		unquoted, err = appendLoneSurrogate(iter, unquoted, r, escapeStart, options)
+		if err != nil {
+			return nil, err
+		}
+		r, escapeStart = second, secondStart
+	}
+}
+
+func appendLoneSurrogate(iter *iterator, unquoted []byte, r rune, escapeStart int, options *DecodeOptions) ([]byte, error) {
+	switch options.LoneSurrogates {
+	case RejectLoneSurrogates:
+		return nil, newValidationError(iter, ErrInvalidEscape, escapeStart, "the other half of the surrogate pair", "The escape sequence %q is half of a surrogate pair", iter.Slice(escapeStart, escapeStart+6))
+	case KeepLoneSurrogates:
+		// this is what utf8.EncodeRune would do for a 3 byte rune if it didn't refuse surrogates
+		return append(unquoted, 0xe0|byte(r>>12), 0x80|byte(r>>6)&0x3f, 0x80|byte(r)&0x3f), nil
+	default:
+		return appendRune(unquoted, utf8.RuneError), nil
+	}
+}
+
+// scanHex reads the 4 hex digits of a \u escape. It starts on the u and finishes just after the last digit.
+func scanHex(iter *iterator, escapeStart int) (rune, error) {
+	var r rune
+	iter.Next()
+	for i := 0; i < 4; i++ {
+		char := iter.Current()
+		switch {
+		case '0' <= char && char <= '9':
+			r = r<<4 | rune(char-'0')
+		case 'a' <= char && char <= 'f':
+			r = r<<4 | rune(char-'a'+10)
+		case 'A' <= char && char <= 'F':
+			r = r<<4 | rune(char-'A'+10)
+		default:
+			return 0, withRule(newValidationError(iter, ErrInvalidEscape, escapeStart, "4 hex digits after \\u", "Invalid unicode escape sequence %q in string", iter.Slice(escapeStart, iter.Cursor()+1)), ruleChar)
+		}
+		iter.Next()
+	}
+	return r, nil
+}
+
+func appendRune(unquoted []byte, r rune) []byte {
+	var encoded [utf8.UTFMax]byte
+	n := utf8.EncodeRune(encoded[:], r)
+	return append(unquoted, encoded[:n]...)
+}
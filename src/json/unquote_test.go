@@ -0,0 +1,128 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanString(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []TestCase{
+		{"No escapes", []byte(`"abc ሴ"`), "abc ሴ"},
+		{"Simple escapes", []byte(`"\"\\\/\b\f\n\r\t"`), "\"\\/\b\f\n\r\t"},
+		{"Unicode escape", []byte(`"\u0061\u00e9b\u1234"`), "aébሴ"},
+		{"Upper case hex", []byte(`"\u00E9"`), "é"},
+		{"Surrogate pair", []byte(`"\ud83d\ude00"`), "😀"},
+		{"Lone high surrogate", []byte(`"a\ud83db"`), "a\ufffdb"},
+		{"Lone low surrogate", []byte(`"\ude00"`), "\ufffd"},
+		{"High surrogate then another escape", []byte(`"\ud83d\n"`), "\ufffd\n"},
+		{"High surrogate then a normal unicode escape", []byte(`"\ud83dA"`), "\ufffdA"},
+		{"Two high surrogates", []byte(`"\ud83d\ud83d\ude00"`), "\ufffd😀"},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				output, err := scanString(&iterator{s: testcase.input}, &DecodeOptions{})
+				assert.Nil(err)
+				assert.Equal(testcase.expectedOutput, output)
+			},
+		)
+	}
+}
+
+func TestLoneSurrogatePolicies(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte(`["\ud83d\ude00", "a\ud83d"]`)
+
+	value, err := DecodeOptions{LoneSurrogates: KeepLoneSurrogates}.Decode(input)
+	assert.Nil(err)
+	assert.Equal([]any{"😀", "a\xed\xa0\xbd"}, value)
+
+	err = DecodeOptions{LoneSurrogates: RejectLoneSurrogates}.Validate(input)
+	validationErr, ok := err.(ValidationError)
+	if assert.True(ok) {
+		assert.Equal(19, validationErr.Offset)
+		assert.Equal("/1", validationErr.Path)
+	}
+	assert.Nil(Validate(input))
+}
+
+func TestInvalidUTF8Policies(t *testing.T) {
+	assert := assert.New(t)
+	input := []byte("[\"h\xc3\xa9\", \"a\xffb\xc3\"]")
+
+	err := Validate(input)
+	validationErr, ok := err.(ValidationError)
+	if assert.True(ok) {
+		assert.Equal(10, validationErr.Offset)
+		assert.Equal("/1", validationErr.Path)
+		assert.True(errors.Is(validationErr, ErrInvalidString))
+	}
+
+	value, err := DecodeOptions{InvalidUTF8: ReplaceInvalidUTF8}.Decode(input)
+	assert.Nil(err)
+	assert.Equal([]any{"hé", "a\ufffdb\ufffd"}, value)
+
+	value, err = DecodeOptions{InvalidUTF8: KeepInvalidUTF8}.Decode(input)
+	assert.Nil(err)
+	assert.Equal([]any{"hé", "a\xffb\xc3"}, value)
+
+	// a rune split between reads is still valid
+	decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(`"😀é" "\xe2\x82"`)))
+	value, err = decoder.Decode()
+	assert.Nil(err)
+	assert.Equal("😀é", value)
+	_, err = decoder.Decode()
+	if assert.Error(err) {
+		assert.Equal(10, err.(*SyntaxError).Offset)
+	}
+}
+
+func TestScanStringErrors(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		name   string
+		input  []byte
+		offset int
+	}{
+		{"Go only escape", []byte(`"ab\x41"`), 3},
+		{"Bell escape", []byte(`"\a"`), 1},
+		{"Octal escape", []byte(`"\101"`), 1},
+		{"Single quote escape", []byte(`"\'"`), 1},
+		{"Short unicode escape", []byte(`"\u12"`), 1},
+		{"Bad hex in second half of a pair", []byte(`"\ud83d\uzzzz"`), 7},
+		{"Raw newline", []byte("\"ab\ncd\""), 3},
+		{"Raw tab", []byte("\"\t\""), 1},
+		{"Raw nul", []byte("\"\x00\""), 1},
+		{"Unterminated", []byte(`"abc\"`), 6},
+		{"Bad escape in a key", []byte(`{"a\q": 1}`), 3},
+	}
+	for _, testcase := range testCases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err, ok := Validate(testcase.input).(ValidationError)
+				if assert.True(ok) {
+					assert.Equal(testcase.offset, err.Offset)
+				}
+			},
+		)
+	}
+}
+
+func TestScanStringFromStream(t *testing.T) {
+	assert := assert.New(t)
+	long := strings.Repeat(`😀ab\n`, streamChunkSize)
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(`"` + long + `" "é"`)))
+	value, err := dec.Decode()
+	assert.Nil(err)
+	assert.Equal(strings.Repeat("😀ab\n", streamChunkSize), value)
+	value, err = dec.Decode()
+	assert.Nil(err)
+	assert.Equal("é", value)
+}
@@ -1,222 +1,186 @@
 package json
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
-	"unicode"
+	"io"
+	"strings"
+	"unicode/utf8"
 )
 
-// ValidationError type
+// These say what kind of problem a ValidationError, SyntaxError or LimitError is, so that callers can tell them
+// apart with errors.Is instead of by their messages.
+var (
+	// ErrUnexpectedEOF is for input that ends before the json does. It can be as well as one of the others,
+	// e.g. for `1e` both errors.Is(err, ErrUnexpectedEOF) and errors.Is(err, ErrInvalidNumber) are true.
+	ErrUnexpectedEOF = errors.New("unexpected end of json input")
+	// ErrUnexpectedCharacter is for a character that can't be where it is, like a missing comma
+	ErrUnexpectedCharacter = errors.New("unexpected character")
+	ErrInvalidLiteral      = errors.New("invalid literal")
+	ErrInvalidNumber       = errors.New("invalid number")
+	// ErrInvalidString is for control characters and invalid UTF-8 in a string
+	ErrInvalidString = errors.New("invalid string")
+	ErrInvalidEscape = errors.New("invalid escape sequence")
+	ErrDuplicateKey  = errors.New("duplicate key")
+	// ErrTrailingData is for anything but whitespace after the json value
+	ErrTrailingData = errors.New("data after the json value")
+	// ErrLimitExceeded is for all the LimitErrors and ErrDepthExceeded just for DepthLimit
+	ErrLimitExceeded = errors.New("limit exceeded")
+	ErrDepthExceeded = errors.New("nesting depth exceeded")
+)
+
+// ValidationError describes where and why a json string is invalid.
 type ValidationError struct {
 	msg string
+	// kind is one of the errors above
+	kind error
+	// Offset is the byte offset in the input where the problem was detected.
+	Offset int
+	// Line and Column are 1-based. Column counts runes, not bytes.
+	Line   int
+	Column int
+	// Path is the JSON Pointer (RFC 6901) of the value that encloses the problem, e.g. /items/3/name.
+	// It is empty when the problem is in the top level value.
+	Path string
+	// Expected describes what should have been at Offset and Found what was actually there.
+	Expected string
+	Found    string
+	// Rule is the rule of the RFC 8259 grammar that was broken, e.g. "int = zero / ( digit1-9 *DIGIT )"
+	Rule string
+	// Hint is set if the problem looks like a common mistake, e.g. a trailing comma. It is also added to the message.
+	Hint *Hint
+	// FirstOffset is only set for a repeated key. It is the offset of the first time the key appeared in
+	// the object and Offset is where it appeared again.
+	FirstOffset int
 }
 
 func (e ValidationError) Error() string {
 	return e.msg
 }
 
-// Validate a json string
-func Validate(s []byte) error {
-	iter := iterator{s: s}
-	err := validate(&iter)
-	if err != nil {
-		return err
-	}
-	iter.AdvancePastAllWhiteSpace()
-	if iter.Cursor() != iter.Len() {
-		return ValidationError{msg: "Extra characters at the end of the json string"}
-	}
-	return nil
+// Unwrap returns the kind of problem, e.g. ErrInvalidNumber
+func (e ValidationError) Unwrap() error {
+	return e.kind
 }
 
-func validate(iter *iterator) error {
-	iter.AdvancePastAllWhiteSpace()
-	switch {
-	case iter.Current() == '[':
-		return validateArray(iter)
-	case iter.Current() == '"':
-		return validateString(iter)
-	case iter.Current() == '{':
-		return validateObject(iter)
-	case iter.Current() == 'n':
-		return validateLiteral(iter, "null")
-	case iter.Current() == 't':
-		return validateLiteral(iter, "true")
-	case iter.Current() == 'f':
-		return validateLiteral(iter, "false")
-	case isNumber(iter):
-		return validateNumber(iter)
-	default:
-		return ValidationError{msg: fmt.Sprintf("Unknown value at %d", iter.Cursor())}
-	}
+// Is makes errors.Is(err, ErrUnexpectedEOF) true for any problem found at the end of the input
+func (e ValidationError) Is(target error) bool {
+	return target == ErrUnexpectedEOF && e.Found == "end of input"
 }
 
-func validateLiteral(iter *iterator, literal string) error {
-	for _, char := range literal {
-		if rune(iter.Current()) != char {
-			return ValidationError{msg: fmt.Sprintf("Error when trying to unmarshall '%v'", literal)}
-		}
-		iter.Next()
+func newValidationError(iter *iterator, kind error, offset int, expected string, msg string, msgArgs ...interface{}) ValidationError {
+	line, column := iter.Position(offset)
+	return ValidationError{
+		msg:      fmt.Sprintf(msg, msgArgs...),
+		kind:     kind,
+		Offset:   offset,
+		Line:     line,
+		Column:   column,
+		Expected: expected,
+		Found:    describeAt(iter, offset),
 	}
-	return nil
 }
 
-func validateNumber(iter *iterator) error {
-	// maybe this should be an explicit state machine
-	hasSign := false
-	if (iter.Current() == '-') || (iter.Current() == '+') {
-		iter.Next()
-		hasSign = true
-	}
-	// there needs to be a digit after - or +
-	if hasSign && !unicode.IsDigit(rune(iter.Current())) {
-		return ValidationError{msg: "There needs to be a digit after - or +"}
-	}
-	for unicode.IsDigit(rune(iter.Current())) {
-		iter.Next()
-	}
-	hasDot := false
-	if iter.Current() == '.' {
-		iter.Next()
-		hasDot = true
-	}
-	if hasDot && !unicode.IsDigit(rune(iter.Current())) {
-		return ValidationError{msg: "There needs to be a digit after . "}
-	}
-	for unicode.IsDigit(rune(iter.Current())) {
-		iter.Next()
+// describeAt is used to fill in ValidationError.Found
+func describeAt(iter *iterator, offset int) string {
+	if offset >= iter.Len() {
+		return "end of input"
 	}
-
-	hasExponent := false
-	if (iter.Current() == 'e') || (iter.Current() == 'E') {
-		hasExponent = true
-		iter.Next()
-	}
-	if (iter.Current() == '-') || (iter.Current() == '+') {
-		iter.Next()
-	}
-	beforeExponentNumber := iter.Current()
-	for unicode.IsDigit(rune(iter.Current())) {
-		iter.Next()
-	}
-	// if we have encountered e/E then make sure there is at least one digit after e/E
-	if hasExponent && (iter.Current()-beforeExponentNumber) == 0 {
-		return ValidationError{msg: "There needs to be at least one digit after e/E when parsing a number"}
-	}
-	return nil
+	char, _ := utf8.DecodeRune(iter.Slice(offset, offset+utf8.UTFMax))
+	return fmt.Sprintf("%q", char)
 }
 
-func validateString(iter *iterator) error {
-	var err error
-	start := iter.Cursor()
-	err = iter.AdvancePast('"')
-	if err != nil {
-		return err
-	}
-
-	// if empty string
-	if iter.Current() == '"' {
-		err = iter.AdvancePast('"')
-		if err != nil {
-			return err
-		}
+// withRule fills in the Rule of err if it doesn't have one yet
+func withRule(err error, rule string) error {
+	if err == nil {
 		return nil
 	}
-	for iter.HasNext() && iter.Current() != '"' {
-		if iter.Current() == '\\' {
-			iter.Next()
-			// skip over an escaped `"` and `\`
-			if (iter.Current() == '"') || (iter.Current() == '\\') {
-				iter.Next()
-			}
-		} else {
-			iter.Next()
-		}
-	}
-	err = iter.AdvancePast('"')
-	if err != nil {
-		return err
-	}
-	_, err = strconv.Unquote(string(iter.SliceTillCursor(start)))
-	if err != nil {
-		return err
+	if validationErr, ok := err.(ValidationError); ok && validationErr.Rule == "" {
+		validationErr.Rule = rule
+		return validationErr
 	}
-	return nil
+	return err
 }
 
-func validateArray(iter *iterator) error {
-	var err error
+// expect is iter.AdvancePast for the parser, it says which rule needed char
+func expect(iter *iterator, char byte, rule string) error {
+	return withRule(iter.AdvancePast(char), rule)
+}
 
-	err = iter.AdvancePast('[')
-	if err != nil {
-		return err
-	}
-	if iter.Current() == ']' {
-		iter.Next()
-		return nil
-	}
-	for iter.HasNext() {
-		err = validate(iter)
-		if err != nil {
-			return err
-		}
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
 
-		iter.AdvancePastAllWhiteSpace()
-		if iter.Current() == ']' {
-			break
-		}
-		err = iter.AdvancePast(',')
-		if err != nil {
-			return err
-		}
-	}
-	err = iter.AdvancePast(']')
-	if err != nil {
-		return err
+// withParent adds segment to the front of the path of err as the error is returned from a nested value
+func withParent(err error, segment string) error {
+	switch e := err.(type) {
+	case ValidationError:
+		e.Path = "/" + pointerEscaper.Replace(segment) + e.Path
+		return e
+	case *LimitError:
+		e.Path = "/" + pointerEscaper.Replace(segment) + e.Path
+		return e
 	}
-	return nil
+	return err
 }
 
-func validateObject(iter *iterator) error {
-	var err error
+// Validate a json string
+func Validate(s []byte) error {
+	return DecodeOptions{}.Validate(s)
+}
 
-	err = iter.AdvancePast('{')
-	if err != nil {
-		return err
-	}
-	if iter.Current() == '}' {
-		iter.Next()
-		return nil
-	}
-	for iter.HasNext() {
-		// key needs to be a string
-		iter.AdvancePastAllWhiteSpace()
-		err = validateString(iter)
-		if err != nil {
-			return ValidationError{msg: errorMsg(iter, "Key needs to be a valid string")}
+// Validate a json string using these options
+func (opts DecodeOptions) Validate(s []byte) error {
+	p := newParser(&iterator{s: s}, noopBuilder{}, &opts)
+	_, err := p.parseDocument()
+	return err
+}
+
+// DefaultMaxErrors is the MaxErrors used when DecodeOptions doesn't set one
+const DefaultMaxErrors = 100
+
+// ValidateAll is Validate but it carries on after a problem to find the rest of them too.
+// After each problem it skips to the next , ] or } of the array or object the problem is in.
+// It stops after DecodeOptions.MaxErrors problems. If data is valid it returns nil.
+func ValidateAll(data []byte) []ValidationError {
+	return DecodeOptions{}.ValidateAll(data)
+}
+
+// ValidateAll is ValidateAll using these options
+func (opts DecodeOptions) ValidateAll(data []byte) []ValidationError {
+	maxErrors := opts.MaxErrors
+	if maxErrors <= 0 {
+		maxErrors = DefaultMaxErrors
+	}
+	tokenizer := opts.NewTokenizer(data)
+	var errs []ValidationError
+	lastOffset := -1
+	for len(errs) < maxErrors {
+		_, err := tokenizer.Next()
+		if err == io.EOF {
+			break
 		}
-		err = iter.AdvancePast(':')
-		if err != nil {
-			return err
+		if err == nil {
+			continue
 		}
-		err = validate(iter)
-		if err != nil {
-			return err
+		var validationErr ValidationError
+		switch e := err.(type) {
+		case ValidationError:
+			validationErr = e
+		case *LimitError:
+			validationErr = e.ValidationError
 		}
-
-		iter.AdvancePastAllWhiteSpace()
-		if iter.Current() == '}' {
+		errs = append(errs, validationErr)
+		if validationErr.Offset == lastOffset {
+			// recovering from here didn't get anywhere so skip a byte
+			tokenizer.iter.Next()
+		}
+		lastOffset = validationErr.Offset
+		if limitErr, ok := err.(*LimitError); ok && limitErr.Limit == InputSizeLimit {
+			// there is no point looking at the rest of it
 			break
 		}
-		err = iter.AdvancePast(',')
-		if err != nil {
-			return err
+		if !tokenizer.recover() {
+			break
 		}
 	}
-	// this should return err
-	err = iter.AdvancePast('}')
-	if err != nil {
-		return err
-	}
-	return nil
+	return errs
 }
@@ -1,6 +1,7 @@
 package json
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,49 +9,198 @@ import (
 
 func TestValidate(t *testing.T) {
 	assert := assert.New(t)
-	testcases := []TestCase{
+	testcases := []struct {
+		name  string
+		input []byte
+		// the kinds of error Validate should return, nil if the input is valid
+		kinds []error
+	}{
 
-		{"Unexpected end of string", []byte(`"k1`), ValidationError{msg: "Was expecting '\"' but we are at the end"}},
+		{"Unexpected end of string", []byte(`"k1`), []error{ErrUnexpectedEOF, ErrUnexpectedCharacter}},
 		{"empty string", []byte(`""`), nil},
 		{"single quote in string", []byte(`"'"`), nil},
 		{"double quote in string", []byte(`"\""`), nil},
 		{"slash in string", []byte(`"\\"`), nil},
+		{"bad escape", []byte(`"\x"`), []error{ErrInvalidEscape}},
+		{"bad unicode escape", []byte(`"\u12x4"`), []error{ErrInvalidEscape}},
+		{"control character in string", []byte("\"\t\""), []error{ErrInvalidString}},
 
 		{"standalone number", []byte(`12234`), nil},
-		{"number with extras at the end", []byte(`1234tr`), ValidationError{msg: "Extra characters at the end of the json string"}},
+		{"number with extras at the end", []byte(`1234tr`), []error{ErrInvalidNumber}},
 		{"number with exponent", []byte(`1234e123`), nil},
-		{"number with exponent with eE", []byte(`1234eE123`), ValidationError{msg: "There needs to be at least one digit after e/E when parsing a number"}},
-		{"number with exponent without a digit", []byte(`1234e`), ValidationError{msg: "There needs to be at least one digit after e/E when parsing a number"}},
+		{"number with exponent with eE", []byte(`1234eE123`), []error{ErrInvalidNumber}},
+		{"number with exponent without a digit", []byte(`1234e`), []error{ErrInvalidNumber, ErrUnexpectedEOF}},
 		{"fraction with exponent", []byte(`0.12e123`), nil},
 		{"fraction with positive exponent", []byte(`0.12e+123`), nil},
 		{"fraction with negative exponent", []byte(`0.12e-123`), nil},
-		{". without number after", []byte(`0.`), ValidationError{msg: "There needs to be a digit after . "}},
-		{"- on its own", []byte(`-`), ValidationError{msg: "There needs to be a digit after - or +"}},
-		{"+ on its own", []byte(`+`), ValidationError{msg: "There needs to be a digit after - or +"}},
+		{". without number after", []byte(`0.`), []error{ErrInvalidNumber, ErrUnexpectedEOF}},
+		{"- on its own", []byte(`-`), []error{ErrInvalidNumber, ErrUnexpectedEOF}},
+		{"+ on its own", []byte(`+`), []error{ErrInvalidNumber}},
 		{"zero with exponent", []byte(`0e10`), nil},
 		{"one then fraction", []byte(`1.34`), nil},
 
 		{"true", []byte(`true`), nil},
 		{"false", []byte(`false`), nil},
 		{"null", []byte(`null`), nil},
+		{"misspelt literal", []byte(`nul`), []error{ErrInvalidLiteral, ErrUnexpectedEOF}},
+		{"literal with extras at the end", []byte(`falsey`), []error{ErrInvalidLiteral}},
 
-		{"Unexpected end of array", []byte(`["k1",`), ValidationError{msg: "Was expecting ']' but we are at the end"}},
+		{"Unexpected end of array", []byte(`["k1",`), []error{ErrUnexpectedEOF, ErrUnexpectedCharacter}},
 		{"empty array", []byte(`[]`), nil},
 		{"array with mixed types", []byte(`[1234, true]`), nil},
+		{"missing comma", []byte(`[1 2]`), []error{ErrUnexpectedCharacter}},
 
 		{"empty object", []byte(`{}`), nil},
-		{"Unexpected end of object", []byte(`{"k1":"v1"`), ValidationError{msg: "Was expecting ',' but we are at the end"}},
-		{"object with key type that is not string", []byte(`{1234: true}`), ValidationError{msg: "There is an error around {1̳234: true}. Key needs to be a valid string"}},
+		{"Unexpected end of object", []byte(`{"k1":"v1"`), []error{ErrUnexpectedEOF, ErrUnexpectedCharacter}},
+		{"object with key type that is not string", []byte(`{1234: true}`), []error{ErrUnexpectedCharacter}},
 
-		{"More character at the end", []byte(`"12234", 123`), ValidationError{msg: "Extra characters at the end of the json string"}},
+		{"More character at the end", []byte(`"12234", 123`), []error{ErrTrailingData}},
 		{"More spaces at the end", []byte(`"12234"  `), nil},
 	}
+	allKinds := []error{ErrUnexpectedEOF, ErrUnexpectedCharacter, ErrInvalidLiteral, ErrInvalidNumber, ErrInvalidString, ErrInvalidEscape, ErrDuplicateKey, ErrTrailingData, ErrLimitExceeded, ErrDepthExceeded}
 	for _, testcase := range testcases {
 		t.Run(
 			testcase.name,
 			func(t *testing.T) {
-				assert.Equal(testcase.expectedOutput, Validate(testcase.input))
+				err := Validate(testcase.input)
+				if testcase.kinds == nil {
+					assert.Nil(err)
+					return
+				}
+				for _, kind := range allKinds {
+					expected := false
+					for _, expectedKind := range testcase.kinds {
+						expected = expected || kind == expectedKind
+					}
+					assert.Equal(expected, errors.Is(err, kind), kind.Error())
+				}
+				// Decode wraps it in a SyntaxError, which is still the same kind
+				_, decodeErr := Decode(testcase.input)
+				assert.True(errors.Is(decodeErr, testcase.kinds[0]))
+				var syntaxErr *SyntaxError
+				assert.True(errors.As(decodeErr, &syntaxErr))
 			},
 		)
 	}
 }
+
+func TestValidationErrorPosition(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		name     string
+		input    []byte
+		expected ValidationError
+	}{
+		{"end of string", []byte(`"k1`), ValidationError{kind: ErrUnexpectedCharacter, Offset: 3, Line: 1, Column: 4, Path: "", Expected: `'"'`, Found: "end of input", Rule: ruleString}},
+		{"missing comma", []byte("{\n  \"items\": [1 2]\n}"), ValidationError{kind: ErrUnexpectedCharacter, Offset: 16, Line: 2, Column: 15, Path: "/items", Expected: "','", Found: "'2'", Rule: ruleArray}},
+		{"nested value", []byte(`{"items": [{}, {}, {}, {"name": tru}]}`), ValidationError{kind: ErrInvalidLiteral, Offset: 35, Line: 1, Column: 36, Path: "/items/3/name", Expected: "true", Found: "'}'", Rule: literalRules["true"]}},
+		{"escaped key", []byte(`{"a/b~c": [-]}`), ValidationError{kind: ErrInvalidNumber, Offset: 12, Line: 1, Column: 13, Path: "/a~1b~0c/0", Expected: "a digit", Found: "']'", Rule: ruleInt}},
+		{"column counts runes", []byte(`["héllo" x]`), ValidationError{kind: ErrUnexpectedCharacter, Offset: 10, Line: 1, Column: 10, Path: "", Expected: "','", Found: "'x'", Rule: ruleArray}},
+		{"extra characters", []byte("1\n 2"), ValidationError{kind: ErrTrailingData, Offset: 3, Line: 2, Column: 2, Path: "", Expected: "end of input", Found: "'2'", Rule: ruleJSONText}},
+	}
+	for _, testcase := range testcases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err, ok := Validate(testcase.input).(ValidationError)
+				if !assert.True(ok) {
+					return
+				}
+				err.msg = ""
+				err.Hint = nil
+				assert.Equal(testcase.expected, err)
+			},
+		)
+	}
+}
+
+func TestStrictAndLenient(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		name    string
+		input   []byte
+		rule    string
+		lenient any
+	}{
+		{"leading +", []byte(`+1`), ruleNumber, int64(1)},
+		{"leading + on a float", []byte(`+123.0`), ruleNumber, 123.0},
+		{"leading zero", []byte(`0123`), ruleInt, int64(123)},
+		{"negative leading zero", []byte(`-01`), ruleInt, int64(-1)},
+		{"literal runs into the next token", []byte(`[true"a"]`), ruleValue, nil},
+		{"number runs into the next token", []byte(`[1{}]`), ruleNumber, nil},
+	}
+	for _, testcase := range testcases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				err, ok := Validate(testcase.input).(ValidationError)
+				if assert.True(ok) {
+					assert.Equal(testcase.rule, err.Rule)
+				}
+				value, err2 := DecodeOptions{Lenient: true}.Decode(testcase.input)
+				if testcase.lenient == nil {
+					// these are still not json, lenient only finds the problem later
+					assert.Error(err2)
+					return
+				}
+				assert.NoError(err2)
+				assert.Equal(testcase.lenient, value)
+			},
+		)
+	}
+	assert.True(errors.Is(Validate([]byte(`1x`)), ErrInvalidNumber))
+	assert.True(errors.Is(Validate([]byte(`truex`)), ErrInvalidLiteral))
+	for _, input := range []string{`0`, `-0`, `0.5`, `-0e1`, `[1,true,null]`, `{"a":false}`, "[1\n]"} {
+		assert.NoError(Validate([]byte(input)), input)
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	assert := assert.New(t)
+	type problem struct {
+		Offset int
+		Path   string
+	}
+	testcases := []struct {
+		name     string
+		input    string
+		expected []problem
+	}{
+		{"valid", `{"a": [1, 2]}`, nil},
+		{"one problem", `[1, x]`, []problem{{4, "/1"}}},
+		{"problems in different values", `{"a": tru, "b": [1,], "c": 01, "d": true}`, []problem{{9, "/a"}, {19, "/b/1"}, {28, "/c"}}},
+		{"missing comma", `[1 2, 3 4, 5]`, []problem{{3, ""}, {8, ""}}},
+		{"missing colon", `{"a" 1, "b": x}`, []problem{{5, ""}, {13, "/b"}}},
+		{"bad key", `{"a\q": 1, 2: 3, "c": [}`, []problem{{3, ""}, {11, ""}, {23, "/c/0"}}},
+		{"nested problem is skipped over", `[{"a": [1 2]}, x]`, []problem{{10, "/0/a"}, {15, "/1"}}},
+		{"unclosed", `[1, [2, `, []problem{{8, "/1/1"}}},
+		{"extra characters", `1 2`, []problem{{2, ""}}},
+		// the ] closes the object and the array, so the last one is extra
+		{"wrong closing bracket", `[1, {"a": 2]]`, []problem{{11, "/1"}, {12, ""}}},
+		{"only a comma", `{,}`, []problem{{1, ""}}},
+		{"extra commas", `[,1,,2]`, []problem{{1, "/0"}, {4, "/1"}}},
+	}
+	for _, testcase := range testcases {
+		t.Run(
+			testcase.name,
+			func(t *testing.T) {
+				var problems []problem
+				for _, err := range ValidateAll([]byte(testcase.input)) {
+					problems = append(problems, problem{err.Offset, err.Path})
+				}
+				assert.Equal(testcase.expected, problems)
+			},
+		)
+	}
+
+	// the first one is always what Validate finds
+	input := []byte(`{"a": [1, 2,], "b": {"c": nul}, "d" 3}`)
+	errs := ValidateAll(input)
+	assert.Len(errs, 3)
+	assert.Equal(Validate(input), errs[0])
+
+	input = []byte(`[x, x, x, x, x]`)
+	assert.Len(ValidateAll(input), 5)
+	assert.Len(DecodeOptions{MaxErrors: 2}.ValidateAll(input), 2)
+	assert.Len(DecodeOptions{MaxInputSize: 3}.ValidateAll(input), 1)
+}
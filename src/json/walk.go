@@ -0,0 +1,88 @@
+package json
+
+import (
+	"io"
+)
+
+// Handler is told about each part of a json document as Walk reads it.
+// If a method returns an error, Walk stops and returns that error.
+type Handler interface {
+	OnObjectStart() error
+	OnKey(key string) error
+	OnObjectEnd() error
+	OnArrayStart() error
+	OnArrayEnd() error
+	// OnValue is called for strings, numbers, booleans and null with the same Go value Unmarshall would produce.
+	OnValue(value any) error
+}
+
+// NoopHandler does nothing. Embed it in a Handler to only implement the methods you need.
+type NoopHandler struct{}
+
+// OnObjectStart does nothing
+func (NoopHandler) OnObjectStart() error { return nil }
+
+// OnKey does nothing
+func (NoopHandler) OnKey(key string) error { return nil }
+
+// OnObjectEnd does nothing
+func (NoopHandler) OnObjectEnd() error { return nil }
+
+// OnArrayStart does nothing
+func (NoopHandler) OnArrayStart() error { return nil }
+
+// OnArrayEnd does nothing
+func (NoopHandler) OnArrayEnd() error { return nil }
+
+// OnValue does nothing
+func (NoopHandler) OnValue(value any) error { return nil }
+
+// Walk reads the json document in data and calls the methods of h as it goes.
+// It is the walk that Validate and Unmarshall share, but nothing is built so what to keep is up to h.
+// The methods of h may be called before a problem later in data is found, in which case Walk returns
+// a ValidationError like Validate would.
+func Walk(data []byte, h Handler) error {
+	return DecodeOptions{}.Walk(data, h)
+}
+
+// Walk is Walk using these options
+func (opts DecodeOptions) Walk(data []byte, h Handler) error {
+	tokenizer := opts.NewTokenizer(data)
+	for {
+		token, err := tokenizer.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch token.Kind {
+		case ObjectStart:
+			err = h.OnObjectStart()
+		case ObjectEnd:
+			err = h.OnObjectEnd()
+		case ArrayStart:
+			err = h.OnArrayStart()
+		case ArrayEnd:
+			err = h.OnArrayEnd()
+		case Key:
+			err = h.OnKey(token.Text)
+		case String:
+			err = h.OnValue(token.Text)
+		case Bool:
+			err = h.OnValue(token.Bool)
+		case Null:
+			err = h.OnValue(nil)
+		case NumberLiteral:
+			var value any
+			value, err = DefaultBuilder{Numbers: opts.Numbers}.Number(token.Raw)
+			if err != nil {
+				return withFramePath(numberError(tokenizer.iter, token.Offset, err), tokenizer.stack, true)
+			}
+			err = h.OnValue(value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package json
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder writes down every call so the order can be checked
+type recorder struct {
+	calls []any
+}
+
+func (r *recorder) OnObjectStart() error { r.calls = append(r.calls, "{"); return nil }
+func (r *recorder) OnKey(key string) error {
+	r.calls = append(r.calls, "key "+key)
+	return nil
+}
+func (r *recorder) OnObjectEnd() error      { r.calls = append(r.calls, "}"); return nil }
+func (r *recorder) OnArrayStart() error     { r.calls = append(r.calls, "["); return nil }
+func (r *recorder) OnArrayEnd() error       { r.calls = append(r.calls, "]"); return nil }
+func (r *recorder) OnValue(value any) error { r.calls = append(r.calls, value); return nil }
+
+// stringCounter only counts strings and stops after limit of them
+type stringCounter struct {
+	NoopHandler
+	count int
+	limit int
+}
+
+var errTooManyStrings = errors.New("too many strings")
+
+func (c *stringCounter) OnValue(value any) error {
+	if _, ok := value.(string); ok {
+		c.count++
+	}
+	if c.count > c.limit {
+		return errTooManyStrings
+	}
+	return nil
+}
+
+func TestWalk(t *testing.T) {
+	assert := assert.New(t)
+	r := &recorder{}
+	err := Walk([]byte(`{"k1": ["v1", 12, 1.5, true, null], "k2": {}}`), r)
+	assert.Nil(err)
+	assert.Equal([]any{"{", "key k1", "[", "v1", int64(12), 1.5, true, nil, "]", "key k2", "{", "}", "}"}, r.calls)
+}
+
+func TestWalkStops(t *testing.T) {
+	assert := assert.New(t)
+
+	counter := &stringCounter{limit: 5}
+	assert.Nil(Walk([]byte(`["a", 1, "b", ["c"]]`), counter))
+	assert.Equal(3, counter.count)
+
+	counter = &stringCounter{limit: 1}
+	err := Walk([]byte(`["a", 1, "b", "c", x]`), counter)
+	assert.Equal(errTooManyStrings, err)
+	assert.Equal(2, counter.count)
+
+	r := &recorder{}
+	err = Walk([]byte(`["a", 1 2]`), r)
+	assert.Equal(Validate([]byte(`["a", 1 2]`)), err)
+	assert.Equal([]any{"[", "a", int64(1)}, r.calls)
+
+	validationErr, ok := Walk([]byte(`[1, 99999999999999999999]`), r).(ValidationError)
+	if assert.True(ok) {
+		assert.Equal(4, validationErr.Offset)
+	}
+
+	// a number that can't be converted is the same error Decode gives
+	for _, testcase := range []struct {
+		options DecodeOptions
+		input   string
+	}{
+		{DecodeOptions{}, `{"a": [99999999999999999999]}`},
+		{DecodeOptions{Numbers: Float64Only}, `{"a": [1, {"b": 1e400}]}`},
+	} {
+		walkErr := testcase.options.Walk([]byte(testcase.input), &recorder{})
+		_, decodeErr := testcase.options.Decode([]byte(testcase.input))
+		assert.Equal(decodeErr, &SyntaxError{walkErr.(ValidationError)})
+		assert.True(errors.Is(walkErr, ErrInvalidNumber))
+	}
+	validationErr = DecodeOptions{Numbers: Float64Only}.Walk([]byte(`{"a": [1, {"b": 1e400}]}`), &recorder{}).(ValidationError)
+	assert.Equal("/a/1/b", validationErr.Path)
+	assert.Equal("a number", validationErr.Expected)
+}
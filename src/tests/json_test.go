@@ -40,7 +40,7 @@ func TestUnmarshall(t *testing.T) {
 				"k4": null,
 				"k5": true,
 				"k6": false,
-				"k	6": null,
+				"k\t6": null,
 				"k7": 123456
 			}`), map[string]any{
 				"k1": "v1",